@@ -2,39 +2,187 @@ package main
 
 import (
 	"context"
-	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"fmt"
+	"github.com/rapido-labs/firebase-ctl/internal/fanout"
 	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
 	"github.com/spf13/cobra"
 	"log"
 )
 
+var onlyKeys []string
+var onlyFiles []string
+var allowDeletes bool
+var maxChanges int
+var assumeYes bool
+var applyMessage string
+
 var applyConfig = &cobra.Command{
 	Use:   "remote-config",
-	Short: "backup remote-config resources from Firebase project",
+	Short: "publish the remote-config in input-dir to the Firebase project",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		clientStore, err := firebase.GetClientStore(ctx)
+		dirs, err := expandInputDirs(inputDirs)
+		if err != nil {
+			log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		opts := remoteconfig.PlanOptions{Only: onlyKeys, OnlyFiles: onlyFiles, AllowDeletes: allowDeletes, Message: applyMessage}
+		if cmd.Flags().Changed("max-changes") {
+			opts.MaxChanges = &maxChanges
+		}
+		if len(dirs) > 1 {
+			applyTargets(ctx, dirs, opts)
+			return
+		}
+		inputDir = dirs[0]
+
+		client, err := targetClient(ctx, inputDir)
 		if err != nil {
 			log.Fatalf("Error while getting firebase app: %s", err.Error())
 		}
-		cfg, err := clientStore.GetLocalConfig(inputDir)
+		source, err := client.Load(inputDir)
 		if err != nil {
 			log.Fatal("error getting latest config", err)
 			return
 		}
-		err = clientStore.ApplyConfig(*cfg)
+		plan, err := client.Plan(source, opts)
+		if refused, ok := err.(*remoteconfig.SafeguardError); ok {
+			fmt.Print(utils.FormatChangeSummary(refused.Changes))
+			log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if err != nil {
+			log.Fatalf("%serror planning apply: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		fmt.Print(utils.FormatChangeSummary(plan.Changes))
+		if plan.Changes.IsEmpty() {
+			log.Printf("%sremote config is up to date, nothing to apply%s", utils.Green, utils.Reset)
+			return
+		}
+		confirmApply(ctx)
+		result, err := client.Apply(source, plan)
 		if err != nil {
 			log.Fatal("error applying latest config", err)
 			return
 		}
-		log.Printf("%s remote config applied successfully%s", utils.Green, utils.Reset)
+		for _, warning := range result.Warnings {
+			log.Printf("%s%s%s", utils.Yellow, warning.Error(), utils.Reset)
+		}
+		log.Printf("%s remote config applied successfully as version %d%s", utils.Green, result.Version.VersionNumber, utils.Reset)
 
 	},
 }
 
+// confirmApply exits unless --yes is passed or the user confirms on the terminal.
+func confirmApply(ctx context.Context) {
+	if assumeYes {
+		return
+	}
+	if !isTerminal() {
+		log.Fatalf("%srefusing to apply without confirmation, pass --yes when not running in a terminal%s", utils.Red, utils.Reset)
+	}
+	if !confirm(ctx, "Apply these changes?") {
+		log.Fatalf("%sapply cancelled%s", utils.Yellow, utils.Reset)
+	}
+}
+
+// targetPlan is the plan of one of the directories of a fan-out apply.
+type targetPlan struct {
+	client *remoteconfig.Client
+	source *remoteconfig.Source
+	plan   *remoteconfig.Plan
+}
+
+// applyTargets plans every directory, then applies the confirmed plans stage by stage until the first failure.
+func applyTargets(ctx context.Context, dirs []string, opts remoteconfig.PlanOptions) {
+	projects := make([]string, len(dirs))
+	details := make([]string, len(dirs))
+	plans := make([]targetPlan, len(dirs))
+	summaries := make([]string, len(dirs))
+	tasks, err := targetTasks(dirs, true)
+	if err != nil {
+		log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+	}
+	// plans do not change anything, so they are computed without waiting for the earlier stages
+	planTasks, _ := targetTasks(dirs, false)
+	results := fanout.Run(ctx, planTasks, fanout.Options{Parallel: parallel}, func(ctx context.Context, i int) error {
+		client, err := targetClient(ctx, dirs[i])
+		if err != nil {
+			return err
+		}
+		projects[i] = client.Project()
+		source, err := client.Load(dirs[i])
+		if err != nil {
+			return err
+		}
+		plan, err := client.Plan(source, opts)
+		if refused, ok := err.(*remoteconfig.SafeguardError); ok {
+			summaries[i] = utils.FormatChangeSummary(refused.Changes)
+		}
+		if err != nil {
+			return err
+		}
+		summaries[i] = utils.FormatChangeSummary(plan.Changes)
+		plans[i] = targetPlan{client: client, source: source, plan: plan}
+		details[i] = plan.Changes.Summary()
+		return nil
+	})
+	pending := 0
+	for i := range dirs {
+		if summaries[i] == "" {
+			continue
+		}
+		fmt.Printf("=== %s (%s), stage %d\n", dirs[i], projects[i], tasks[i].Stage)
+		fmt.Print(summaries[i])
+		if plans[i].plan != nil && !plans[i].plan.Changes.IsEmpty() {
+			pending++
+		}
+	}
+	if fanout.Failed(results) {
+		for i := range results {
+			results[i].Stage = tasks[i].Stage
+		}
+		printTargetTable(results, projects, details)
+		log.Fatalf("%serror planning apply, nothing was applied%s", utils.Red, utils.Reset)
+	}
+	if pending == 0 {
+		log.Printf("%sremote config is up to date in every target, nothing to apply%s", utils.Green, utils.Reset)
+		return
+	}
+	confirmApply(ctx)
+
+	results = fanout.Run(ctx, tasks, fanout.Options{Parallel: parallel, StopOnFailure: true}, func(ctx context.Context, i int) error {
+		target := plans[i]
+		if target.plan.Changes.IsEmpty() {
+			details[i] = "up to date"
+			return nil
+		}
+		result, err := target.client.Apply(target.source, target.plan)
+		if err != nil {
+			return err
+		}
+		for _, warning := range result.Warnings {
+			log.Printf("%s%s: %s%s", utils.Yellow, dirs[i], warning.Error(), utils.Reset)
+		}
+		details[i] = fmt.Sprintf("%s, published version %d (was %d)", details[i], result.Version.VersionNumber, result.PreviousVersion)
+		return nil
+	})
+	printTargetTable(results, projects, details)
+	if fanout.Failed(results) {
+		log.Fatalf("%sapply stopped after a failure, skipped targets were not applied%s", utils.Red, utils.Reset)
+	}
+	log.Printf("%sremote config applied successfully to %d targets%s", utils.Green, pending, utils.Reset)
+}
+
 func init() {
 	applyCmd.AddCommand(applyConfig)
-	applyConfig.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to output directory")
+	applyConfig.PersistentFlags().StringArrayVar(&inputDirs, "input-dir", nil, "Path to output directory, can be repeated or a glob such as envs/*. Directories are applied in the stages of their tool configs")
 	applyConfig.MarkPersistentFlagRequired("input-dir")
+	applyConfig.PersistentFlags().IntVar(&parallel, "parallel", 4, "Number of directories planned and applied at once within a stage")
+	applyConfig.PersistentFlags().StringSliceVar(&onlyKeys, "only", nil, "Apply only these parameters, leaving the rest of the remote template as it is")
+	applyConfig.PersistentFlags().StringSliceVar(&onlyFiles, "only-file", nil, "Apply only the parameters of these files, relative to input-dir")
+	applyConfig.PersistentFlags().BoolVar(&allowDeletes, "allow-deletes", false, "Allow deleting parameters and conditions")
+	applyConfig.PersistentFlags().IntVar(&maxChanges, "max-changes", 0, "Maximum number of changed parameters and conditions, overrides apply.maxChanges of the tool config")
+	applyConfig.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Apply without asking for confirmation")
+	applyConfig.PersistentFlags().StringVarP(&applyMessage, "message", "m", "", "Description of the published template version, followed by the git commit, branch and author")
 }
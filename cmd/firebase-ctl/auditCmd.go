@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "query the local log of applied resources",
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
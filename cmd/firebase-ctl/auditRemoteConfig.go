@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/cobra"
+)
+
+var auditSince string
+var auditUntil string
+var auditQuery remoteconfig.AuditQuery
+var auditLimit int
+var auditJSON bool
+
+var auditRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "list the remote-config applies recorded in the audit log of a directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		query := auditQuery
+		var err error
+		if query.Since, err = parseAuditTime(auditSince); err != nil {
+			log.Fatalf("%sinvalid --since: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if query.Until, err = parseAuditTime(auditUntil); err != nil {
+			log.Fatalf("%sinvalid --until: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		records, err := remoteconfig.NewLocalClient().Audit(inputDir, query)
+		if err != nil {
+			log.Fatalf("%serror reading audit log: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if auditLimit > 0 && len(records) > auditLimit {
+			records = records[len(records)-auditLimit:]
+		}
+		if auditJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, record := range records {
+				encoder.Encode(record)
+			}
+			return
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "TIME\tACTION\tVERSION\tIDENTITY\tUSER\tCOMMIT\tCHANGES")
+		for _, record := range records {
+			commit := record.Commit
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%d -> %d\t%s\t%s\t%s\t%s\n", record.Time.Local().Format(time.RFC3339), record.Action,
+				record.PreviousVersion, record.Version, record.Identity, record.User, commit, record.Summary)
+		}
+		writer.Flush()
+	},
+}
+
+// parseAuditTime accepts a time in RFC 3339, a date, or a duration meaning that long ago.
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a time like 2006-01-02T15:04:05Z, a date or a duration like 24h, got %s", value)
+	}
+	return t, nil
+}
+
+func init() {
+	auditCmd.AddCommand(auditRemoteConfigCmd)
+	auditRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	auditRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	auditRemoteConfigCmd.PersistentFlags().StringVar(&auditSince, "since", "", "Only list applies at or after this time, date or duration ago")
+	auditRemoteConfigCmd.PersistentFlags().StringVar(&auditUntil, "until", "", "Only list applies before this time, date or duration ago")
+	auditRemoteConfigCmd.PersistentFlags().StringVar(&auditQuery.Identity, "identity", "", "Only list applies by this account or user")
+	auditRemoteConfigCmd.PersistentFlags().StringVar(&auditQuery.Commit, "commit", "", "Only list applies from this commit")
+	auditRemoteConfigCmd.PersistentFlags().Int64Var(&auditQuery.Version, "version", 0, "Only list the apply that published this version")
+	auditRemoteConfigCmd.PersistentFlags().IntVar(&auditLimit, "limit", 0, "Number of most recent applies to list, all when 0")
+	auditRemoteConfigCmd.PersistentFlags().BoolVar(&auditJSON, "json", false, "Print the records as JSON lines")
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "find and remove dead parts of resources",
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+}
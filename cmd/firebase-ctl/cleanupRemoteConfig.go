@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/cobra"
+)
+
+var writeCleanup bool
+
+var cleanupRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "find conditions whose dates have passed, and the conditional values that are dead because of them",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := remoteconfig.NewLocalClient()
+		expired, err := client.ExpiredConditions(inputDir, time.Now())
+		if err != nil {
+			log.Fatalf("%serror reading config from local: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if len(expired) == 0 {
+			log.Printf("%sno expired conditions%s", utils.Green, utils.Reset)
+			return
+		}
+		names := []string{}
+		deadValues := 0
+		for _, c := range expired {
+			log.Printf("%s%s in %s can never match again: %s%s", utils.Yellow, c.Name, c.File, c.Expression, utils.Reset)
+			if len(c.Parameters) != 0 {
+				log.Printf("\tdead conditional values in %s", strings.Join(c.Parameters, ", "))
+			}
+			names = append(names, c.Name)
+			deadValues += len(c.Parameters)
+		}
+		if !writeCleanup {
+			log.Printf("%d expired conditions, run with --write to remove them and %d conditional values", len(expired), deadValues)
+			return
+		}
+		if err := client.RemoveConditions(inputDir, names); err != nil {
+			log.Fatalf("%serror removing conditions: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		log.Printf("%sremoved %d conditions and %d conditional values%s", utils.Green, len(expired), deadValues, utils.Reset)
+	},
+}
+
+func init() {
+	cleanupCmd.AddCommand(cleanupRemoteConfigCmd)
+	cleanupRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	cleanupRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	cleanupRemoteConfigCmd.PersistentFlags().BoolVar(&writeCleanup, "write", false, "Remove the expired conditions and their conditional values from the sources")
+}
@@ -2,31 +2,137 @@ package main
 
 import (
 	"context"
-	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/rapido-labs/firebase-ctl/internal/fanout"
+	"github.com/rapido-labs/firebase-ctl/internal/github"
 	"github.com/rapido-labs/firebase-ctl/internal/utils"
 	"github.com/spf13/cobra"
-	"log"
 )
 
+var githubPR int
+
 var diffRemoteConfigCmd = &cobra.Command{
 	Use:   "remote-config",
-	Short: "backup remote-config resources from Firebase project",
+	Short: "show the changes applying the remote-config in input-dir would make to the Firebase project",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		clientStore, err := firebase.GetClientStore(ctx)
+		dirs, err := expandInputDirs(inputDirs)
 		if err != nil {
-			log.Fatalf("%serror while getting firebase app: %s%s", utils.Red, err.Error(), utils.Reset)
+			log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		var githubClient *github.Client
+		if githubPR != 0 {
+			if githubClient, err = github.NewClientFromEnv(); err != nil {
+				log.Fatalf("%serror configuring github: %s%s", utils.Red, err.Error(), utils.Reset)
+			}
+		}
+		if len(dirs) > 1 {
+			diffTargets(ctx, dirs, githubClient)
+			return
 		}
-		err = clientStore.GetRemoteConfigDiff(cmd.Flag("input-dir").Value.String())
+		inputDir = dirs[0]
+		changes, err := diffDir(ctx, inputDir)
 		if err != nil {
 			log.Fatalf("%serror computing diff: %s%s", utils.Red, err.Error(), utils.Reset)
 		}
+		fmt.Print(utils.FormatDiff(*changes))
+		if githubClient != nil {
+			if err := commentDiff(ctx, githubClient, inputDir, changes); err != nil {
+				log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+			}
+		}
 	},
 }
 
+// diffDir returns the changes applying the sources in dir would make to the live template of its target.
+func diffDir(ctx context.Context, dir string) (*utils.ChangeSet, error) {
+	client, err := targetClient(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	source, err := client.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return client.Diff(source)
+}
+
+// commentDiff posts the changes of dir to the pull request githubPR.
+func commentDiff(ctx context.Context, githubClient *github.Client, dir string, changes *utils.ChangeSet) error {
+	// one comment per source directory, so that pull requests changing several projects get one comment each
+	marker := commentMarker(dir)
+	// leave room for the marker UpsertComment appends
+	maxLength := github.MaxCommentLength - utf8.RuneCountInString(marker) - 2
+	body := utils.FormatMarkdown(fmt.Sprintf("Remote Config changes for `%s`", dir), *changes, maxLength)
+	comment, err := githubClient.UpsertComment(ctx, githubPR, marker, body)
+	if err != nil {
+		return fmt.Errorf("error commenting on pull request %d: %s", githubPR, err.Error())
+	}
+	log.Printf("%sposted the diff of %s to %s%s", utils.Green, dir, comment.HTMLURL, utils.Reset)
+	return nil
+}
+
+// commentMarker returns the marker of the comment for dir. The directory is hashed after making it relative to the
+// working directory, so that the marker is a valid HTML comment and the same on every runner.
+func commentMarker(dir string) string {
+	path := filepath.Clean(dir)
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(path)))
+	return fmt.Sprintf("<!-- firebase-ctl diff %x -->", sum[:8])
+}
+
+// diffTargets diffs every directory concurrently, then prints their diffs and a table of the results.
+func diffTargets(ctx context.Context, dirs []string, githubClient *github.Client) {
+	tasks, _ := targetTasks(dirs, false)
+	changes := make([]*utils.ChangeSet, len(dirs))
+	projects := make([]string, len(dirs))
+	details := make([]string, len(dirs))
+	results := fanout.Run(ctx, tasks, fanout.Options{Parallel: parallel}, func(ctx context.Context, i int) error {
+		client, err := targetClient(ctx, dirs[i])
+		if err != nil {
+			return err
+		}
+		projects[i] = client.Project()
+		source, err := client.Load(dirs[i])
+		if err != nil {
+			return err
+		}
+		if changes[i], err = client.Diff(source); err != nil {
+			return err
+		}
+		details[i] = changes[i].Summary()
+		if githubClient != nil {
+			return commentDiff(ctx, githubClient, dirs[i], changes[i])
+		}
+		return nil
+	})
+	for i := range dirs {
+		if changes[i] != nil {
+			fmt.Printf("=== %s (%s)\n", dirs[i], projects[i])
+			fmt.Print(utils.FormatDiff(*changes[i]))
+		}
+	}
+	printTargetTable(results, projects, details)
+	if fanout.Failed(results) {
+		log.Fatalf("%serror computing diff of some targets%s", utils.Red, utils.Reset)
+	}
+}
+
 func init() {
 	diffCmd.AddCommand(diffRemoteConfigCmd)
-	diffRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to config directory")
+	diffRemoteConfigCmd.PersistentFlags().StringArrayVar(&inputDirs, "input-dir", nil, "Path to config directory, can be repeated or a glob such as envs/*")
 	diffRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	diffRemoteConfigCmd.PersistentFlags().IntVar(&parallel, "parallel", 4, "Number of directories diffed at once")
+	diffRemoteConfigCmd.PersistentFlags().IntVar(&githubPR, "github-pr", 0, "Post the diff as a comment on this pull request, updating the comment of earlier runs. Needs GITHUB_TOKEN and GITHUB_REPOSITORY")
 }
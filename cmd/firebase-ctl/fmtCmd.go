@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "rewrite resource files in canonical form",
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/cobra"
+)
+
+var checkFormat bool
+
+var fmtRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "format the remote-config files in input-dir",
+	Run: func(cmd *cobra.Command, args []string) {
+		unformatted, err := remoteconfig.NewLocalClient().Format(inputDir, checkFormat)
+		if err != nil {
+			log.Fatalf("%serror formatting config: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if !checkFormat {
+			for _, file := range unformatted {
+				log.Printf("formatted %s", file)
+			}
+			return
+		}
+		if len(unformatted) != 0 {
+			for _, file := range unformatted {
+				log.Printf("%s%s is not formatted%s", utils.Red, file, utils.Reset)
+			}
+			log.Fatalf("%s%d files need formatting, run firebase-ctl fmt remote-config%s", utils.Red, len(unformatted), utils.Reset)
+		}
+		log.Printf("%sall files are formatted%s", utils.Green, utils.Reset)
+	},
+}
+
+func init() {
+	fmtCmd.AddCommand(fmtRemoteConfigCmd)
+	fmtRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	fmtRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	fmtRemoteConfigCmd.PersistentFlags().BoolVar(&checkFormat, "check", false, "Report unformatted files without modifying them")
+}
@@ -1,32 +1,34 @@
 package main
 
 import (
-	"context"
-	"github.com/rapido-labs/firebase-ctl/internal/utils"
 	"log"
 
-	"github.com/rapido-labs/firebase-ctl/internal/firebase"
-
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
 	"github.com/spf13/cobra"
 )
 
 var outputDir string
+var splitConditionsBy string
+var parameterLayout string
+var defaultParametersFile string
 
 var getRemoteConfigCmd = &cobra.Command{
 	Use:   "remote-config",
 	Short: "backup remote-config resources from Firebase project",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
+		ctx := cmd.Context()
 
-		clientStore, err := firebase.GetClientStore(ctx)
+		client, err := newClient(ctx)
 		if err != nil {
 			log.Fatalf("%serror while getting firebase app: %s%s", utils.Red, err.Error(), utils.Reset)
 		}
-		latestRemoteConfig, err := clientStore.GetLatestRemoteConfig()
-		if err != nil {
-			log.Fatalf("%serror getting latest remote config: %s%s", utils.Red, err.Error(), utils.Reset)
-		}
-		err = clientStore.BackupRemoteConfig(latestRemoteConfig, outputDir)
+		err = client.Get(outputDir, remoteconfig.WriteOptions{
+			SplitConditionsBy:     splitConditionsBy,
+			ParameterLayout:       parameterLayout,
+			DefaultParametersFile: defaultParametersFile,
+		})
 		if err != nil {
 			log.Fatalf("%serror backing up remote config: %s%s", utils.Red, err.Error(), utils.Reset)
 		}
@@ -39,4 +41,7 @@ func init() {
 	getCmd.AddCommand(getRemoteConfigCmd)
 	getRemoteConfigCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "Path to output directory")
 	getRemoteConfigCmd.MarkPersistentFlagRequired("output-dir")
+	getRemoteConfigCmd.PersistentFlags().StringVar(&splitConditionsBy, "split-by", remoteconfig.SplitByNone, "Layout of the conditions directory: none or prefix")
+	getRemoteConfigCmd.PersistentFlags().StringVar(&parameterLayout, "layout", remoteconfig.LayoutDefault, "File for parameters not present in output-dir yet: default, prefix or group")
+	getRemoteConfigCmd.PersistentFlags().StringVar(&defaultParametersFile, "default-file", config.ParametersFile, "File name used by the default layout")
 }
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "show the published versions of resources",
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
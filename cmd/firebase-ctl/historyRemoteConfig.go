@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+var historyCommit string
+
+var historyRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "list remote-config template versions along with the git commits that produced them",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		client, err := newClient(ctx)
+		if err != nil {
+			log.Fatalf("%serror while getting firebase app: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		limit := historyLimit
+		if historyCommit != "" {
+			limit = 0
+		}
+		versions, err := client.History(limit)
+		if err != nil {
+			log.Fatalf("%serror listing versions: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tUPDATED\tUSER\tCOMMIT\tDESCRIPTION")
+		for _, version := range versions {
+			commit := utils.CommitFromDescription(version.Description)
+			if historyCommit != "" && (commit == "" || !strings.HasPrefix(commit, historyCommit) && !strings.HasPrefix(historyCommit, commit)) {
+				continue
+			}
+			user := ""
+			if version.UpdateUser != nil {
+				user = version.UpdateUser.Email
+			}
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", version.VersionNumber, version.UpdateTime.Format(time.RFC3339), user, commit, version.Description)
+		}
+		writer.Flush()
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyRemoteConfigCmd)
+	historyRemoteConfigCmd.PersistentFlags().IntVar(&historyLimit, "limit", 20, "Number of versions to list, all when 0")
+	historyRemoteConfigCmd.PersistentFlags().StringVar(&historyCommit, "commit", "", "Only list the versions published from this commit")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/rapido-labs/firebase-ctl/internal/lsp"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "run a language server for the source files over stdio",
+	Run: func(cmd *cobra.Command, args []string) {
+		// stdout carries the protocol, so logs must go to stderr
+		log.SetOutput(os.Stderr)
+		remote := func() (*remoteconfig.Template, error) {
+			client, err := newClient(cmd.Context())
+			if err != nil {
+				return nil, err
+			}
+			return client.Remote()
+		}
+		server := lsp.NewServer(afero.NewOsFs(), remote)
+		done := make(chan error, 1)
+		go func() {
+			done <- server.Serve(os.Stdin, os.Stdout)
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Fatalf("%serror serving language server: %s%s", utils.Red, err.Error(), utils.Reset)
+			}
+		case <-cmd.Context().Done():
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
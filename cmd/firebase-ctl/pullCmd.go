@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "merge remote resources into existing sources",
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+}
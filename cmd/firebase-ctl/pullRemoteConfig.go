@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/cobra"
+)
+
+var configDir string
+
+var pullRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "merge the latest remote-config template into the sources in config-dir",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		client, err := newClient(ctx)
+		if err != nil {
+			log.Fatalf("%serror while getting firebase app: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		result, err := client.Pull(configDir, remoteconfig.WriteOptions{
+			ParameterLayout:       parameterLayout,
+			DefaultParametersFile: defaultParametersFile,
+		})
+		if err != nil {
+			log.Fatalf("%serror pulling remote config: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		for _, key := range result.Updated {
+			log.Printf("%supdated from remote: %s%s", utils.Green, key, utils.Reset)
+		}
+		if len(result.Conflicts) != 0 {
+			for _, conflict := range result.Conflicts {
+				log.Printf("%sconflict in %s %s: %s%s", utils.Red, conflict.Kind, conflict.Key, conflict.Reason, utils.Reset)
+			}
+			log.Fatalf("%s%d conflicts were left unresolved in %s%s", utils.Red, len(result.Conflicts), configDir, utils.Reset)
+		}
+		log.Printf("%ssuccessfully pulled the config into %s%s", utils.Green, configDir, utils.Reset)
+	},
+}
+
+func init() {
+	pullCmd.AddCommand(pullRemoteConfigCmd)
+	pullRemoteConfigCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "Path to config directory")
+	pullRemoteConfigCmd.MarkPersistentFlagRequired("config-dir")
+	pullRemoteConfigCmd.PersistentFlags().StringVar(&parameterLayout, "layout", remoteconfig.LayoutDefault, "File for parameters not present in config-dir yet: default, prefix or group")
+	pullRemoteConfigCmd.PersistentFlags().StringVar(&defaultParametersFile, "default-file", config.ParametersFile, "File name used by the default layout")
+}
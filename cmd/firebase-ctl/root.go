@@ -1,20 +1,69 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
 	"github.com/spf13/cobra"
 )
 
+var endpoint string
+var projectID string
+var timeout time.Duration
+var retries int
+
 var rootCmd = &cobra.Command{
 	Use:   "firebase-ctl",
 	Short: "firebase-ctl can be used to get, apply, show diff of remote config resources",
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(interruptibleContext()); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// interruptibleContext returns a context cancelled by the first interrupt or termination signal, so that remote calls
+// are aborted cleanly. A second signal exits immediately.
+func interruptibleContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Printf("%sinterrupted, cancelling%s", utils.Yellow, utils.Reset)
+		cancel()
+		<-signals
+		os.Exit(130)
+	}()
+	return ctx
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&endpoint, "endpoint", "", "Base url of a Remote Config REST endpoint to use instead of Firebase, e.g. an emulator")
+	rootCmd.PersistentFlags().StringVar(&projectID, "project", remoteconfig.DefaultProject, "Project to use with --endpoint")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", remoteconfig.DefaultTimeout, "Timeout of every remote call")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", remoteconfig.DefaultMaxRetries, "Number of times a remote call failing with a transient error is retried, 0 to disable")
+}
+
+// newClient returns the library client for the backend selected by the global flags.
+func newClient(ctx context.Context) (*remoteconfig.Client, error) {
+	return remoteconfig.NewClient(ctx, clientOptions())
+}
+
+// clientOptions returns the library options for the backend selected by the global flags.
+func clientOptions() remoteconfig.Options {
+	maxRetries := retries
+	if maxRetries == 0 {
+		maxRetries = -1
+	}
+	return remoteconfig.Options{Endpoint: endpoint, ProjectID: projectID, Timeout: timeout, MaxRetries: maxRetries}
+}
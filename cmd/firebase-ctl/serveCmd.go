@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve local resources over http",
+}
+
+// shutdownTimeout is how long requests in flight are given to finish after an interrupt.
+const shutdownTimeout = 5 * time.Second
+
+// listenAndServe serves handler on addr until ctx is done, then shuts the server down gracefully.
+func listenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	server := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
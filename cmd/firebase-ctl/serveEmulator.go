@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+
+	"github.com/rapido-labs/firebase-ctl/internal/emulator"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var emulatorDataDir string
+var listenAddr string
+
+var serveEmulatorCmd = &cobra.Command{
+	Use:   "emulator",
+	Short: "serve the Remote Config REST API backed by local files",
+	Run: func(cmd *cobra.Command, args []string) {
+		server := emulator.NewServer(afero.NewOsFs(), emulatorDataDir)
+		log.Printf("%sRemote Config emulator listening on http://%s, data in %s%s", utils.Green, listenAddr, emulatorDataDir, utils.Reset)
+		log.Printf("point firebase-ctl at it with --endpoint http://%s", listenAddr)
+		if err := listenAndServe(cmd.Context(), listenAddr, server); err != nil {
+			log.Fatalf("%serror serving emulator: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(serveEmulatorCmd)
+	serveEmulatorCmd.PersistentFlags().StringVar(&emulatorDataDir, "data-dir", "", "Directory the template versions are stored in")
+	serveEmulatorCmd.MarkPersistentFlagRequired("data-dir")
+	serveEmulatorCmd.PersistentFlags().StringVar(&listenAddr, "addr", "localhost:9010", "Address to listen on")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/condition"
+	"github.com/rapido-labs/firebase-ctl/internal/emulator"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/cobra"
+)
+
+var fetchAddr string
+var pollInterval time.Duration
+
+var serveRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "serve the local config to apps through the client fetch api, reloading it when the sources change",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := remoteconfig.NewLocalClient()
+		server := emulator.NewFetchServer()
+		reload := func() {
+			source, err := client.Load(inputDir)
+			if err != nil {
+				log.Printf("%serror reading config from local, still serving the previous config: %s%s", utils.Red, err.Error(), utils.Reset)
+				return
+			}
+			for _, c := range source.Config.Conditions {
+				if _, err := condition.Parse(c.Expression); err != nil {
+					log.Printf("%sinvalid expression for condition %s, still serving the previous config: %s%s", utils.Red, c.Name, err.Error(), utils.Reset)
+					return
+				}
+			}
+			server.Update(source.Config)
+			log.Printf("%sloaded %d parameters and %d conditions from %s%s", utils.Green, len(source.Config.Parameters), len(source.Config.Conditions), inputDir, utils.Reset)
+		}
+		reload()
+		go client.Watch(cmd.Context(), inputDir, pollInterval, reload)
+
+		log.Printf("serving fetch requests on http://%s/v1/projects/<project>/namespaces/firebase:fetch", fetchAddr)
+		if err := listenAndServe(cmd.Context(), fetchAddr, server); err != nil {
+			log.Fatalf("%serror serving remote config: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(serveRemoteConfigCmd)
+	serveRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	serveRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	serveRemoteConfigCmd.PersistentFlags().StringVar(&fetchAddr, "addr", "localhost:9011", "Address to listen on")
+	serveRemoteConfigCmd.PersistentFlags().DurationVar(&pollInterval, "poll-interval", time.Second, "How often the sources are checked for changes")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/fanout"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+)
+
+var inputDirs []string
+var parallel int
+
+// expandInputDirs returns the source directories named by patterns, in the order given. Patterns may be globs, such
+// as envs/*, whose matches are sorted; files among the matches are ignored.
+func expandInputDirs(patterns []string) ([]string, error) {
+	dirs := []string{}
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input-dir %s: %s", pattern, err.Error())
+		}
+		found := false
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			found = true
+			if !seen[match] {
+				seen[match] = true
+				dirs = append(dirs, match)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("input-dir %s matches no directory", pattern)
+		}
+	}
+	return dirs, nil
+}
+
+// targetClient returns the library client for the source directory dir: the backend of the target in its tool
+// config, falling back to the one selected by the global flags.
+func targetClient(ctx context.Context, dir string) (*remoteconfig.Client, error) {
+	toolConfig, err := config.LoadToolConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	return remoteconfig.NewClient(ctx, clientOptions().ForTarget(toolConfig.Target))
+}
+
+// targetTasks returns a task for every directory. With staged set, the directories are staged by the targets of their
+// tool configs, or applied one after the other in the order of dirs when none of them sets a stage.
+func targetTasks(dirs []string, staged bool) ([]fanout.Task, error) {
+	tasks := make([]fanout.Task, len(dirs))
+	unstaged := []string{}
+	for i, dir := range dirs {
+		tasks[i] = fanout.Task{Name: dir}
+		if !staged {
+			continue
+		}
+		toolConfig, err := config.LoadToolConfig(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", dir, err.Error())
+		}
+		tasks[i].Stage = toolConfig.Target.Stage
+		if tasks[i].Stage == 0 {
+			unstaged = append(unstaged, dir)
+		}
+	}
+	switch {
+	case staged && len(unstaged) == len(dirs):
+		for i := range tasks {
+			tasks[i].Stage = i + 1
+		}
+	case len(unstaged) != 0:
+		return nil, fmt.Errorf("%s set no target.stage while other directories do, set one in every directory or in none", strings.Join(unstaged, ", "))
+	}
+	return tasks, nil
+}
+
+// printTargetTable prints a row for every target with its project, its outcome and details, the error of the targets
+// that failed. Stages are shown when there is more than one.
+func printTargetTable(results []fanout.Result, projects, details []string) {
+	staged := false
+	for _, result := range results {
+		staged = staged || result.Stage != results[0].Stage
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if staged {
+		fmt.Fprintln(writer, "TARGET\tPROJECT\tSTAGE\tSTATUS\tDETAILS")
+	} else {
+		fmt.Fprintln(writer, "TARGET\tPROJECT\tSTATUS\tDETAILS")
+	}
+	for i, result := range results {
+		status, detail := "ok", details[i]
+		switch {
+		case result.Skipped:
+			status, detail = "skipped", ""
+		case result.Err != nil:
+			// errors listing several problems span lines, which would break the table
+			status, detail = "failed", strings.Join(strings.Fields(result.Err.Error()), " ")
+		}
+		if staged {
+			fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\n", result.Name, projects[i], result.Stage, status, detail)
+		} else {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", result.Name, projects[i], status, detail)
+		}
+	}
+	writer.Flush()
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "find where application code uses resources",
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log"
+	"sort"
+
+	"github.com/rapido-labs/firebase-ctl/internal/usage"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var scanDirs []string
+var strictUsage bool
+var accessorReceiver string
+
+var usageRemoteConfigCmd = &cobra.Command{
+	Use:   "remote-config",
+	Short: "report parameters application code never refers to, and code reading keys missing from the config",
+	Run: func(cmd *cobra.Command, args []string) {
+		source, err := remoteconfig.NewLocalClient().Load(inputDir)
+		if err != nil {
+			log.Fatalf("%serror reading config from local: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		keys := make([]string, 0, len(source.Config.Parameters))
+		for key := range source.Config.Parameters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		report, err := usage.Scan(afero.NewOsFs(), scanDirs, keys, accessorReceiver)
+		if err != nil {
+			log.Fatalf("%serror scanning sources: %s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		for _, key := range report.Unused {
+			log.Printf("%sunused parameter %s%s", utils.Yellow, key, utils.Reset)
+		}
+		for _, reference := range report.Unknown {
+			log.Printf("%s%s:%d reads %s, which is not in the config%s", utils.Red, reference.File, reference.Line, reference.Key, utils.Reset)
+		}
+		log.Printf("scanned %d files: %d of %d parameters unused, %d references to unknown keys", report.FilesScanned, len(report.Unused), len(keys), len(report.Unknown))
+		if strictUsage && (len(report.Unused) != 0 || len(report.Unknown) != 0) {
+			log.Fatalf("%susage check failed%s", utils.Red, utils.Reset)
+		}
+	},
+}
+
+func init() {
+	usageCmd.AddCommand(usageRemoteConfigCmd)
+	usageRemoteConfigCmd.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	usageRemoteConfigCmd.MarkPersistentFlagRequired("input-dir")
+	usageRemoteConfigCmd.PersistentFlags().StringSliceVar(&scanDirs, "scan", nil, "Source directory to scan, can be repeated")
+	usageRemoteConfigCmd.MarkPersistentFlagRequired("scan")
+	usageRemoteConfigCmd.PersistentFlags().StringVar(&accessorReceiver, "receiver", usage.DefaultReceiver, "Regular expression matching the Remote Config client that accessors are called on")
+	usageRemoteConfigCmd.PersistentFlags().BoolVar(&strictUsage, "strict", false, "Fail when unused parameters or unknown keys are found")
+}
@@ -2,58 +2,235 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/rapido-labs/firebase-ctl/internal/config"
-	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"github.com/rapido-labs/firebase-ctl/internal/fanout"
+	"github.com/rapido-labs/firebase-ctl/internal/report"
 	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/rapido-labs/firebase-ctl/pkg/remoteconfig"
 	"github.com/spf13/cobra"
+	"io"
 	"log"
+	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
 var inputDir string
+var watch bool
+var reportFormat string
+var reportOutput string
 var validateConfig = &cobra.Command{
 	Use:   "remote-config",
 	Short: "validate remote-config by performing a dry-run",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
-		isRemoteValidationEnabled := true
-		clientStore, err := firebase.GetClientStore(ctx)
+		ctx := cmd.Context()
+		dirs, err := expandInputDirs(inputDirs)
 		if err != nil {
-			isRemoteValidationEnabled = false
+			log.Fatalf("%s%s%s", utils.Red, err.Error(), utils.Reset)
+		}
+		if len(dirs) > 1 {
+			if watch {
+				log.Fatalf("%s--watch validates a single --input-dir%s", utils.Red, utils.Reset)
+			}
+			validateTargets(ctx, dirs)
+			return
+		}
+		inputDir = dirs[0]
+		if watch {
+			watchLocalValidation(ctx)
+			return
+		}
+		client, isRemoteValidationEnabled := validationClient(ctx, inputDir)
+		if !isRemoteValidationEnabled {
 			log.Printf("%scould not find google application credentials. remote validation will not be available%s", utils.Yellow, utils.Reset)
 		}
-		localConfig, err := clientStore.GetLocalConfig(inputDir)
+		validationReport, err := client.Report(inputDir)
 		if err != nil {
-			log.Fatalf("%serror reading config from local: %s%s", utils.Red, err.Error(), utils.Reset)
+			log.Fatalf("%serror validating config: %s%s", utils.Red, err.Error(), utils.Reset)
 		}
-		errs := utils.ValidateParameters(localConfig.Parameters)
-		if len(errs) != 0 {
-			errStringBuilder := strings.Builder{}
-			for j := range errs {
-				errStringBuilder.WriteString("\n\t" + errs[j].Error())
+		if !validationReport.HasErrors() {
+			log.Printf("%sConfigValidation: Local validation successful %s", utils.Green, utils.Reset)
+			if isRemoteValidationEnabled {
+				validateOnRemote(client, inputDir, validationReport)
 			}
-			log.Fatalf("%serror validating parameter values: %s%s", utils.Red, errStringBuilder.String(), utils.Reset)
-			return
 		}
-		log.Printf("%sConfigValidation: Local validation successful %s", utils.Green, utils.Reset)
-		if !isRemoteValidationEnabled {
-			return
+		if err := writeReport(validationReport); err != nil {
+			log.Fatalf("%serror writing report: %s%s", utils.Red, err.Error(), utils.Reset)
 		}
-		if _, err := config.GetFirebaseConfig(); err != nil {
-			log.Printf("cannot initiate remoteConfigClient: %s", err.Error())
+		if validationReport.HasErrors() {
+			log.Fatalf("%serror validating config: %d errors, %d warnings%s", utils.Red,
+				validationReport.Count(config.SeverityError), validationReport.Count(config.SeverityWarn), utils.Reset)
 		}
-		err = clientStore.ValidateOnRemote(*localConfig)
+	},
+}
+
+// validationClient returns the client validating dir, and whether it can validate on remote. Without credentials
+// the sources are only validated offline.
+func validationClient(ctx context.Context, dir string) (*remoteconfig.Client, bool) {
+	client, err := targetClient(ctx, dir)
+	if err != nil {
+		return remoteconfig.NewLocalClient(), false
+	}
+	return client, true
+}
+
+// validateOnRemote has the backend validate the sources in dir, adding its verdict to validationReport.
+func validateOnRemote(client *remoteconfig.Client, dir string, validationReport *remoteconfig.Report) {
+	source, err := client.Load(dir)
+	if err == nil {
+		err = client.ValidateOnRemote(source)
+	}
+	if err != nil {
+		validationReport.Add(remoteconfig.Problem{Severity: config.SeverityError, Rule: remoteconfig.RuleRemoteValidation, Message: err.Error()})
+		return
+	}
+	log.Printf("%sRemote validation of %s successful %s", utils.Green, dir, utils.Reset)
+}
+
+// validateTargets validates every directory concurrently, then writes a single report of their problems and prints a
+// table of the results. Problems that are not about a file are attributed to their directory.
+func validateTargets(ctx context.Context, dirs []string) {
+	tasks, _ := targetTasks(dirs, false)
+	reports := make([]*remoteconfig.Report, len(dirs))
+	projects := make([]string, len(dirs))
+	details := make([]string, len(dirs))
+	results := fanout.Run(ctx, tasks, fanout.Options{Parallel: parallel}, func(ctx context.Context, i int) error {
+		client, remote := validationClient(ctx, dirs[i])
+		projects[i] = client.Project()
+		r, err := client.Report(dirs[i])
 		if err != nil {
-			log.Fatal("error validating with remote api", err)
-			return
+			return err
+		}
+		if !r.HasErrors() && remote {
+			validateOnRemote(client, dirs[i], r)
+		}
+		reports[i] = r
+		details[i] = fmt.Sprintf("%d errors, %d warnings", r.Count(config.SeverityError), r.Count(config.SeverityWarn))
+		if !remote {
+			details[i] += ", not validated on remote"
+		}
+		if r.HasErrors() {
+			return errors.New(details[i])
 		}
-		log.Printf("%sRemote validation successful %s", utils.Green, utils.Reset)
+		return nil
+	})
+	merged := &remoteconfig.Report{}
+	for i, r := range reports {
+		if r == nil {
+			continue
+		}
+		for _, problem := range r.Problems {
+			if problem.File == "" {
+				problem.File = dirs[i]
+			}
+			merged.Add(problem)
+		}
+	}
+	if err := writeReport(merged); err != nil {
+		log.Fatalf("%serror writing report: %s%s", utils.Red, err.Error(), utils.Reset)
+	}
+	printTargetTable(results, projects, details)
+	if fanout.Failed(results) {
+		log.Fatalf("%serror validating config: %d errors, %d warnings%s", utils.Red,
+			merged.Count(config.SeverityError), merged.Count(config.SeverityWarn), utils.Reset)
+	}
+}
 
-	},
+// writeReport writes validationReport in reportFormat to reportOutput. Text reports for the terminal are logged in
+// color instead.
+func writeReport(validationReport *remoteconfig.Report) error {
+	if reportOutput == "" && (reportFormat == report.FormatText || reportFormat == "") {
+		for _, problem := range validationReport.Problems {
+			color := utils.Yellow
+			if problem.Severity == config.SeverityError {
+				color = utils.Red
+			}
+			log.Printf("%s%s%s", color, problem.String(), utils.Reset)
+		}
+		return nil
+	}
+	var out io.Writer = os.Stdout
+	if reportOutput != "" {
+		file, err := os.Create(reportOutput)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return validationReport.Write(out, reportFormat)
+}
+
+// localProblems loads and validates the sources in inputDir, returning every problem found.
+func localProblems(client *remoteconfig.Client) []string {
+	validationReport, err := client.Report(inputDir)
+	if err != nil {
+		return []string{"error validating config: " + err.Error()}
+	}
+	problems := []string{}
+	for _, problem := range validationReport.Problems {
+		problems = append(problems, problem.String())
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// watchLocalValidation validates the sources after every change, printing the problems that appeared or were fixed
+// since the previous run.
+func watchLocalValidation(ctx context.Context) {
+	client := remoteconfig.NewLocalClient()
+	previous := localProblems(client)
+	for _, problem := range previous {
+		log.Printf("%s%s%s", utils.Red, problem, utils.Reset)
+	}
+	printValidationStatus(previous)
+	log.Printf("watching %s for changes", inputDir)
+	client.Watch(ctx, inputDir, pollInterval, func() {
+		current := localProblems(client)
+		for _, problem := range missingFrom(previous, current) {
+			log.Printf("%sfixed: %s%s", utils.Green, problem, utils.Reset)
+		}
+		for _, problem := range missingFrom(current, previous) {
+			log.Printf("%snew: %s%s", utils.Red, problem, utils.Reset)
+		}
+		printValidationStatus(current)
+		previous = current
+	})
+}
+
+func printValidationStatus(problems []string) {
+	if len(problems) == 0 {
+		log.Printf("%sConfigValidation: Local validation successful %s", utils.Green, utils.Reset)
+		return
+	}
+	log.Printf("%sConfigValidation: %d problems%s", utils.Red, len(problems), utils.Reset)
+}
+
+// missingFrom returns the problems of a that are not in b.
+func missingFrom(a, b []string) []string {
+	inB := map[string]bool{}
+	for _, problem := range b {
+		inB[problem] = true
+	}
+	missing := []string{}
+	for _, problem := range a {
+		if !inB[problem] {
+			missing = append(missing, problem)
+		}
+	}
+	return missing
 }
 
 func init() {
 	validateCmd.AddCommand(validateConfig)
-	validateConfig.PersistentFlags().StringVar(&inputDir, "input-dir", "", "Path to input directory")
+	validateConfig.PersistentFlags().StringArrayVar(&inputDirs, "input-dir", nil, "Path to input directory, can be repeated or a glob such as envs/*")
 	validateConfig.MarkPersistentFlagRequired("input-dir")
+	validateConfig.PersistentFlags().IntVar(&parallel, "parallel", 4, "Number of directories validated at once")
+	validateConfig.PersistentFlags().BoolVar(&watch, "watch", false, "Validate offline again after every change to the sources")
+	validateConfig.PersistentFlags().StringVar(&reportFormat, "format", report.FormatText, "Format of the report, one of "+strings.Join(report.Formats, ", "))
+	validateConfig.PersistentFlags().StringVar(&reportOutput, "output", "", "Write the report to this file instead of the terminal")
+	validateConfig.PersistentFlags().DurationVar(&pollInterval, "poll-interval", time.Second, "How often the sources are checked for changes in watch mode")
 }
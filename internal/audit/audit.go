@@ -0,0 +1,153 @@
+// Package audit keeps a local log of the template versions published by firebase-ctl, recording who published them,
+// from which commit and why, which the version history of Firebase does not.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Actions recorded in the log.
+const (
+	ActionApply = "apply"
+	// ActionApplyUnknown is an apply whose publish got no answer, so the template may or may not have been published.
+	// Its Version is 0.
+	ActionApplyUnknown = "apply-unknown"
+)
+
+// Record is one line of the log, describing a published template version.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	// Identity is the account of the credentials the template was published with, such as the email of a service
+	// account. It is empty for unauthenticated endpoints.
+	Identity string `json:"identity,omitempty"`
+	// User is the person who ran firebase-ctl.
+	User            string `json:"user,omitempty"`
+	Project         string `json:"project,omitempty"`
+	Directory       string `json:"directory"`
+	PreviousVersion int64  `json:"previousVersion"`
+	Version         int64  `json:"version"`
+	Summary         string `json:"summary"`
+	// ChangesHash and PlanHash are the hashes of the change set and of the published template, which tell whether two
+	// applies made the same changes and published the same template.
+	ChangesHash string `json:"changesHash"`
+	PlanHash    string `json:"planHash"`
+	Commit      string `json:"commit,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Hash returns the sha256 of the JSON encoding of v, prefixed with the algorithm.
+func Hash(v interface{}) (string, error) {
+	contents, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(contents)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Append adds record to the log at path, creating it if needed.
+func Append(path string, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read returns the records of the log at path, oldest first. A log that does not exist has no records.
+func Read(path string) ([]Record, error) {
+	records := []Record{}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err.Error())
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Query selects records. Zero fields match every record.
+type Query struct {
+	Since time.Time
+	Until time.Time
+	// Identity matches the identity or the user, ignoring case.
+	Identity string
+	Project  string
+	// Commit matches commits it is a prefix of, or that are a prefix of it.
+	Commit string
+	// Version matches the record of the apply that published it.
+	Version int64
+	Action  string
+}
+
+// Match tells whether record is selected by q.
+func (q Query) Match(record Record) bool {
+	if !q.Since.IsZero() && record.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !record.Time.Before(q.Until) {
+		return false
+	}
+	if q.Identity != "" && !strings.EqualFold(q.Identity, record.Identity) && !strings.EqualFold(q.Identity, record.User) {
+		return false
+	}
+	if q.Project != "" && q.Project != record.Project {
+		return false
+	}
+	if q.Commit != "" && (record.Commit == "" || !strings.HasPrefix(record.Commit, q.Commit) && !strings.HasPrefix(q.Commit, record.Commit)) {
+		return false
+	}
+	if q.Version != 0 && q.Version != record.Version {
+		return false
+	}
+	return q.Action == "" || q.Action == record.Action
+}
+
+// Filter returns the records selected by q.
+func Filter(records []Record, q Query) []Record {
+	selected := []Record{}
+	for _, record := range records {
+		if q.Match(record) {
+			selected = append(selected, record)
+		}
+	}
+	return selected
+}
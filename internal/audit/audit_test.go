@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestAudit(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+func (c *AuditTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	c.dir = dir
+}
+
+func (c *AuditTestSuite) TearDownTest() {
+	os.RemoveAll(c.dir)
+}
+
+func (c *AuditTestSuite) TestAppendAndRead() {
+	path := filepath.Join(c.dir, ".firebase-ctl", "audit.jsonl")
+	records, err := Read(path)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), records)
+
+	first := Record{Time: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC), Action: ActionApply, Identity: "deploy@project.iam.gserviceaccount.com", Version: 1, Commit: "3f2a9c1e"}
+	second := Record{Time: time.Date(2021, 3, 2, 10, 0, 0, 0, time.UTC), Action: ActionApply, User: "Jane Doe", PreviousVersion: 1, Version: 2}
+	assert.NoError(c.T(), Append(path, first))
+	assert.NoError(c.T(), Append(path, second))
+	records, err = Read(path)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []Record{first, second}, records)
+
+	ioutil.WriteFile(path, []byte("{\"version\": 1}\n\n{\"version\": \"2\"}\n"), 0644)
+	_, err = Read(path)
+	assert.EqualError(c.T(), err, path+":3: json: cannot unmarshal string into Go struct field Record.version of type int64")
+}
+
+func (c *AuditTestSuite) TestQuery() {
+	records := []Record{
+		{Time: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC), Action: ActionApply, Identity: "deploy@project.iam.gserviceaccount.com", Version: 1, Commit: "3f2a9c1e"},
+		{Time: time.Date(2021, 3, 2, 10, 0, 0, 0, time.UTC), Action: ActionApply, User: "Jane Doe", Version: 2},
+	}
+	assert.Equal(c.T(), records, Filter(records, Query{}))
+	assert.Equal(c.T(), records[1:], Filter(records, Query{Since: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)}))
+	assert.Equal(c.T(), records[:1], Filter(records, Query{Until: records[1].Time}))
+	assert.Equal(c.T(), records[1:], Filter(records, Query{Identity: "jane doe"}))
+	assert.Equal(c.T(), records[:1], Filter(records, Query{Commit: "3f2a9c1"}))
+	assert.Equal(c.T(), records[1:], Filter(records, Query{Version: 2}))
+	assert.Empty(c.T(), Filter(records, Query{Project: "other"}))
+}
+
+func (c *AuditTestSuite) TestHash() {
+	hash, err := Hash(map[string]int{"a": 1})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "sha256:015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862", hash)
+}
@@ -0,0 +1,476 @@
+// Package condition parses Remote Config condition expressions and evaluates them locally against the context of a
+// device. Percent conditions are approximated, see Percentile.
+package condition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a parsed condition expression.
+type Expression interface {
+	Eval(ctx *Context) (bool, error)
+	String() string
+}
+
+// Literal is the constant true or false.
+type Literal struct {
+	Value bool
+}
+
+// Not negates an expression.
+type Not struct {
+	X Expression
+}
+
+// Logical joins two expressions with && or ||.
+type Logical struct {
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+// Comparison applies an operator to an attribute of the device, e.g. device.os == 'ios' or
+// app.version.>=(['2.0.0']).
+type Comparison struct {
+	Subject  Subject
+	Operator string
+	Values   []Value
+	// Method is set when the operator was written as a method call taking a list.
+	Method bool
+}
+
+// Subject is the attribute a comparison is about. Key is set for keyed attributes like app.userProperty['key'],
+// Seed for percent('seed').
+type Subject struct {
+	Name string
+	Key  string
+	Seed string
+}
+
+// ValueKind tells which field of a Value is set.
+type ValueKind int
+
+const (
+	StringValue ValueKind = iota
+	NumberValue
+	DateTimeValue
+)
+
+// Value is an operand of a comparison.
+type Value struct {
+	Kind   ValueKind
+	Text   string
+	Number float64
+	// Time and Zone are set for dateTime('2021-08-31T00:00:00', 'Asia/Calcutta') values.
+	Time time.Time
+	Zone string
+}
+
+// Parse parses a condition expression.
+func Parse(expression string) (Expression, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+// Walk calls fn for every comparison in the expression, in source order.
+func Walk(expr Expression, fn func(*Comparison)) {
+	switch e := expr.(type) {
+	case *Not:
+		Walk(e.X, fn)
+	case *Logical:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *Comparison:
+		fn(e)
+	}
+}
+
+// UnknownAttributes returns the attributes of the expression that are not known to this package, in source order.
+// Comparisons on them are accepted as written and evaluate to false locally.
+func UnknownAttributes(expr Expression) []string {
+	unknown := []string{}
+	Walk(expr, func(c *Comparison) {
+		if _, ok := attributes[c.Subject.Name]; !ok {
+			unknown = append(unknown, c.Subject.Name)
+		}
+	})
+	return unknown
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.text == text
+}
+
+func (p *parser) isIdent(text string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.text == text
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.isPunct(text) {
+		return fmt.Errorf("expected %q at position %d, found %s", text, p.peek().pos, p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return fmt.Errorf("unexpected %s at position %d", p.peek(), p.peek().pos)
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.isPunct("||") {
+		p.next()
+		var right Expression
+		right, err = p.parseAnd()
+		left = &Logical{Operator: "||", Left: left, Right: right}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.isPunct("&&") {
+		p.next()
+		var right Expression
+		right, err = p.parseUnary()
+		left = &Logical{Operator: "&&", Left: left, Right: right}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	if p.isPunct("!") {
+		p.next()
+		x, err := p.parseUnary()
+		return &Not{X: x}, err
+	}
+	if p.isPunct("(") {
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expectPunct(")")
+	}
+	if p.isIdent("true") || p.isIdent("false") {
+		return &Literal{Value: p.next().text == "true"}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	start := p.peek()
+	subject, err := p.parseSubject()
+	if err != nil {
+		return nil, err
+	}
+	attr, known := attributes[subject.Name]
+	comparison := &Comparison{Subject: subject}
+	t := p.peek()
+	switch {
+	case t.kind == tokenPunct && t.text == ".":
+		// method call: app.version.>=(['1.0']) or app.userProperty['x'].contains(['a'])
+		p.next()
+		method := p.next()
+		if method.kind != tokenIdent && method.kind != tokenPunct {
+			return nil, fmt.Errorf("expected a method at position %d, found %s", method.pos, method)
+		}
+		comparison.Operator = method.text
+		comparison.Method = true
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		if comparison.Values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	case t.kind == tokenIdent && t.text == "in":
+		p.next()
+		comparison.Operator = "in"
+		if comparison.Values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+	case t.kind == tokenIdent && t.text == "between":
+		p.next()
+		lower, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isIdent("and") {
+			return nil, fmt.Errorf("expected \"and\" at position %d, found %s", p.peek().pos, p.peek())
+		}
+		p.next()
+		upper, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Operator = "between"
+		comparison.Values = []Value{lower, upper}
+	case t.kind == tokenPunct && comparisonOperators[t.text]:
+		p.next()
+		comparison.Operator = t.text
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Values = []Value{value}
+	default:
+		return nil, p.unexpected()
+	}
+	if !known {
+		// the backend may support attributes this parser does not know yet, see UnknownAttributes
+		return comparison, nil
+	}
+	if err := attr.check(comparison); err != nil {
+		return nil, fmt.Errorf("%s at position %d", err.Error(), start.pos)
+	}
+	return comparison, nil
+}
+
+var comparisonOperators = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *parser) parseSubject() (Subject, error) {
+	subject := Subject{}
+	first := p.next()
+	if first.kind != tokenIdent {
+		return subject, fmt.Errorf("expected an attribute at position %d, found %s", first.pos, first)
+	}
+	parts := []string{first.text}
+	// a path segment is an identifier after a dot that is not followed by a method call
+	for p.isPunct(".") && p.peekAt(1).kind == tokenIdent && !(p.peekAt(2).kind == tokenPunct && p.peekAt(2).text == "(") {
+		p.next()
+		parts = append(parts, p.next().text)
+	}
+	subject.Name = strings.Join(parts, ".")
+	if p.isPunct("[") {
+		p.next()
+		key := p.next()
+		if key.kind != tokenString {
+			return subject, fmt.Errorf("expected a string key at position %d, found %s", key.pos, key)
+		}
+		subject.Key = key.text
+		if err := p.expectPunct("]"); err != nil {
+			return subject, err
+		}
+	}
+	if subject.Name == "percent" && p.isPunct("(") {
+		p.next()
+		seed := p.next()
+		if seed.kind != tokenString {
+			return subject, fmt.Errorf("expected a string seed at position %d, found %s", seed.pos, seed)
+		}
+		subject.Seed = seed.text
+		if err := p.expectPunct(")"); err != nil {
+			return subject, err
+		}
+	}
+	return subject, nil
+}
+
+func (p *parser) parseList() ([]Value, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+	var values []Value
+	for !p.isPunct("]") {
+		if len(values) != 0 {
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	p.next()
+	return values, nil
+}
+
+func (p *parser) parseValue() (Value, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return Value{Kind: StringValue, Text: t.text}, nil
+	case t.kind == tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid number %s at position %d", t.text, t.pos)
+		}
+		return Value{Kind: NumberValue, Text: t.text, Number: n}, nil
+	case t.kind == tokenPunct && t.text == "(":
+		// app.firstOpenTimestamp <= ('2021-01-01T00:00:00')
+		value, err := p.parseValue()
+		if err != nil {
+			return value, err
+		}
+		return value, p.expectPunct(")")
+	case t.kind == tokenIdent && t.text == "dateTime":
+		return p.parseDateTime(t)
+	}
+	return Value{}, fmt.Errorf("expected a value at position %d, found %s", t.pos, t)
+}
+
+func (p *parser) parseDateTime(start token) (Value, error) {
+	if err := p.expectPunct("("); err != nil {
+		return Value{}, err
+	}
+	args, err := p.parseArguments()
+	if err != nil {
+		return Value{}, err
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return Value{}, fmt.Errorf("dateTime at position %d takes a date and an optional time zone", start.pos)
+	}
+	zone := "UTC"
+	if len(args) == 2 {
+		zone = args[1]
+	}
+	t, err := parseTime(args[0], zone)
+	if err != nil {
+		return Value{}, fmt.Errorf("invalid dateTime at position %d: %s", start.pos, err.Error())
+	}
+	return Value{Kind: DateTimeValue, Text: args[0], Time: t, Zone: zone}, nil
+}
+
+// parseArguments parses string arguments up to and including the closing parenthesis.
+func (p *parser) parseArguments() ([]string, error) {
+	var args []string
+	for !p.isPunct(")") {
+		if len(args) != 0 {
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+		}
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("expected a string at position %d, found %s", t.pos, t)
+		}
+		args = append(args, t.text)
+	}
+	p.next()
+	return args, nil
+}
+
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+func parseTime(value, zone string) (time.Time, error) {
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown time zone %s", zone)
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %s as a date", value)
+}
+
+func (l *Literal) String() string {
+	return strconv.FormatBool(l.Value)
+}
+
+func (n *Not) String() string {
+	if _, ok := n.X.(*Logical); ok {
+		return "!(" + n.X.String() + ")"
+	}
+	return "!" + n.X.String()
+}
+
+func (l *Logical) String() string {
+	return operandString(l.Left, l.Operator) + " " + l.Operator + " " + operandString(l.Right, l.Operator)
+}
+
+// operandString parenthesizes an || inside an &&.
+func operandString(x Expression, operator string) string {
+	if inner, ok := x.(*Logical); ok && inner.Operator != operator && operator == "&&" {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+func (c *Comparison) String() string {
+	subject := c.Subject.String()
+	switch {
+	case c.Method:
+		return subject + "." + c.Operator + "(" + listString(c.Values) + ")"
+	case c.Operator == "in":
+		return subject + " in " + listString(c.Values)
+	case c.Operator == "between":
+		return subject + " between " + c.Values[0].String() + " and " + c.Values[1].String()
+	}
+	return subject + " " + c.Operator + " " + c.Values[0].String()
+}
+
+func (s Subject) String() string {
+	switch {
+	case s.Key != "":
+		return fmt.Sprintf("%s['%s']", s.Name, s.Key)
+	case s.Seed != "":
+		return fmt.Sprintf("%s('%s')", s.Name, s.Seed)
+	}
+	return s.Name
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case NumberValue:
+		return v.Text
+	case DateTimeValue:
+		return fmt.Sprintf("dateTime('%s', '%s')", v.Text, v.Zone)
+	}
+	return "'" + strings.Replace(v.Text, "'", "\\'", -1) + "'"
+}
+
+func listString(values []Value) string {
+	parts := make([]string, len(values))
+	for i := range values {
+		parts[i] = values[i].String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,152 @@
+package condition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionTestSuite struct {
+	suite.Suite
+	ctx *Context
+}
+
+func TestCondition(t *testing.T) {
+	suite.Run(t, new(ConditionTestSuite))
+}
+
+func (c *ConditionTestSuite) SetupTest() {
+	c.ctx = &Context{
+		AppID:          "1:123:android:abc",
+		AppVersion:     "2.10.1",
+		Platform:       "android",
+		Language:       "en-US",
+		Country:        "IN",
+		InstallationID: "installation-1",
+		UserProperties: map[string]string{"tier": "gold", "rides": "12"},
+		Audiences:      []string{"drivers"},
+		Now:            time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (c *ConditionTestSuite) eval(expression string) bool {
+	expr, err := Parse(expression)
+	if !assert.NoError(c.T(), err, expression) {
+		return false
+	}
+	result, err := expr.Eval(c.ctx)
+	assert.NoError(c.T(), err, expression)
+	return result
+}
+
+func (c *ConditionTestSuite) TestMatchingExpressions() {
+	for _, expression := range []string{
+		"true",
+		"device.os == 'ANDROID'",
+		"device.country in ['US', 'IN']",
+		"app.version.>=(['2.9.0'])",
+		"app.version > '2.9'",
+		"app.userProperty['rides'] > 10",
+		"app.userProperty['tier'].contains(['gol'])",
+		"app.userProperty['tier'].matches(['^g.*d$'])",
+		"app.audiences.inAtLeastOne(['drivers', 'riders'])",
+		"dateTime >= dateTime('2021-09-01T05:00:00', 'Asia/Calcutta')",
+		"!(device.os == 'ios') && (app.id == '1:123:android:abc' || false)",
+		"percent between 0 and 100",
+	} {
+		assert.True(c.T(), c.eval(expression), expression)
+	}
+}
+
+func (c *ConditionTestSuite) TestNonMatchingExpressions() {
+	for _, expression := range []string{
+		"false",
+		"device.os == 'ios'",
+		"app.version.<(['2.9.0'])",
+		"app.userProperty['missing'] == 'x'",
+		"app.audiences.inAll(['drivers', 'riders'])",
+		"dateTime < dateTime('2021-08-31T00:00:00', 'Asia/Calcutta')",
+		"app.firstOpenTimestamp <= ('2021-01-01T00:00:00')",
+	} {
+		assert.False(c.T(), c.eval(expression), expression)
+	}
+}
+
+func (c *ConditionTestSuite) TestParseErrors() {
+	for expression, message := range map[string]string{
+		"device.os == 'ios' &&":        "expected an attribute at position 21, found end of expression",
+		"percent <= 120":               "percent is compared with numbers between 0 and 100, found 120 at position 0",
+		"app.userProperty == 'x'":      "app.userProperty needs a key at position 0",
+		"dateTime < dateTime('never')": "invalid dateTime at position 11: cannot parse never as a date",
+		"device.os == 'ios":            "unterminated string at position 13",
+		"app.audiences == 'x'":         "operator == is not supported on app.audiences at position 0",
+	} {
+		_, err := Parse(expression)
+		assert.EqualError(c.T(), err, message, expression)
+	}
+}
+
+func (c *ConditionTestSuite) TestUnknownAttributesAreAccepted() {
+	expr, err := Parse("device.os == 'ios' && device.model in ['pixel'] && app.newAttribute['x'].contains(['a'])")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"device.model", "app.newAttribute"}, UnknownAttributes(expr))
+	assert.False(c.T(), c.eval("device.model == 'pixel' || device.os == 'ios'"))
+}
+
+func (c *ConditionTestSuite) TestString() {
+	expression := "(device.os == 'ios' || app.version.>=(['2.0'])) && !percent('seed') <= 10"
+	expr, err := Parse(expression)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), expression, expr.String())
+}
+
+func (c *ConditionTestSuite) TestPercentileIsStable() {
+	first := Percentile("seed", "installation-1")
+	assert.Equal(c.T(), first, Percentile("seed", "installation-1"))
+	assert.NotEqual(c.T(), first, Percentile("other", "installation-1"))
+	assert.True(c.T(), first >= 0 && first < 100)
+}
+
+func (c *ConditionTestSuite) TestResolve() {
+	config := &model.Config{
+		Conditions: []model.Condition{
+			{Name: "ios", Expression: "device.os == 'ios'"},
+			{Name: "india", Expression: "device.country in ['IN']"},
+			{Name: "android", Expression: "device.os == 'android'"},
+		},
+		Parameters: map[string]model.Parameter{
+			"first_match": {
+				DefaultValue:      &model.ParameterValue{ExplicitValue: "default"},
+				ConditionalValues: map[string]model.ParameterValue{"android": {ExplicitValue: "android"}, "india": {ExplicitValue: "india"}},
+			},
+			"default":     {DefaultValue: &model.ParameterValue{ExplicitValue: "default"}},
+			"app_default": {DefaultValue: &model.ParameterValue{UseInAppDefault: true}},
+		},
+	}
+	entries, err := Resolve(config, c.ctx)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), map[string]string{"first_match": "india", "default": "default"}, entries)
+}
+
+func (c *ConditionTestSuite) TestNeverMatchesAfter() {
+	now := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	for expression, expired := range map[string]bool{
+		"dateTime < dateTime('2021-08-31T00:00:00', 'Asia/Calcutta')":             true,
+		"dateTime < dateTime('2021-09-30T00:00:00')":                              false,
+		"device.os == 'ios' && dateTime <= dateTime('2021-08-01T00:00:00')":       true,
+		"device.os == 'ios' || dateTime < dateTime('2021-08-01T00:00:00')":        false,
+		"!(dateTime >= dateTime('2021-08-01T00:00:00'))":                          true,
+		"dateTime >= dateTime('2021-08-01') && dateTime < dateTime('2021-08-15')": true,
+		"dateTime >= dateTime('2021-08-01') && dateTime < dateTime('2021-09-15')": false,
+		"device.os == 'ios'": false,
+	} {
+		expr, err := Parse(expression)
+		assert.NoError(c.T(), err)
+		assert.Equal(c.T(), expired, NeverMatchesAfter(expr, now), expression)
+	}
+	expr, _ := Parse("dateTime > dateTime('2021-08-01T00:00:00') || device.os == 'ios'")
+	assert.True(c.T(), AlwaysMatchesAfter(expr, now))
+}
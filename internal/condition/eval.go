@@ -0,0 +1,344 @@
+package condition
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Context is what is known about the device a condition is evaluated for. Comparisons on attributes that are not
+// set, or not known to this package, evaluate to false.
+type Context struct {
+	AppID          string
+	AppVersion     string
+	AppBuild       string
+	Platform       string
+	Language       string
+	Country        string
+	InstallationID string
+	UserProperties map[string]string
+	CustomSignals  map[string]string
+	Audiences      []string
+	FirstOpen      time.Time
+	// Now is the time dateTime is compared with; the current time when zero.
+	Now time.Time
+}
+
+type attributeKind int
+
+const (
+	textAttribute attributeKind = iota
+	versionAttribute
+	propertyAttribute
+	timeAttribute
+	percentAttribute
+	audienceAttribute
+)
+
+type attribute struct {
+	kind  attributeKind
+	keyed bool
+}
+
+var attributes = map[string]attribute{
+	"app.id":                     {kind: textAttribute},
+	"app.version":                {kind: versionAttribute},
+	"app.build":                  {kind: versionAttribute},
+	"app.firebaseInstallationId": {kind: textAttribute},
+	"app.userProperty":           {kind: propertyAttribute, keyed: true},
+	"app.customSignal":           {kind: propertyAttribute, keyed: true},
+	"app.audiences":              {kind: audienceAttribute},
+	"app.firstOpenTimestamp":     {kind: timeAttribute},
+	"device.os":                  {kind: textAttribute},
+	"device.language":            {kind: textAttribute},
+	"device.country":             {kind: textAttribute},
+	"dateTime":                   {kind: timeAttribute},
+	"percent":                    {kind: percentAttribute},
+}
+
+var stringMethods = []string{"contains", "notContains", "exactlyMatches", "matches", "startsWith"}
+
+var operatorsByKind = map[attributeKind][]string{
+	textAttribute:     append([]string{"==", "!=", "in"}, stringMethods...),
+	versionAttribute:  append([]string{"==", "!=", "<", "<=", ">", ">=", "in"}, stringMethods...),
+	propertyAttribute: append([]string{"==", "!=", "<", "<=", ">", ">=", "in"}, stringMethods...),
+	timeAttribute:     {"==", "!=", "<", "<=", ">", ">="},
+	percentAttribute:  {"==", "<", "<=", ">", ">=", "between"},
+	audienceAttribute: {"inAtLeastOne", "inAll", "notInAtLeastOne", "notInAll"},
+}
+
+func (a attribute) check(c *Comparison) error {
+	if a.keyed && c.Subject.Key == "" {
+		return fmt.Errorf("%s needs a key", c.Subject.Name)
+	}
+	if !a.keyed && c.Subject.Key != "" {
+		return fmt.Errorf("%s does not take a key", c.Subject.Name)
+	}
+	supported := false
+	for _, operator := range operatorsByKind[a.kind] {
+		supported = supported || operator == c.Operator
+	}
+	if !supported {
+		return fmt.Errorf("operator %s is not supported on %s", c.Operator, c.Subject.Name)
+	}
+	if len(c.Values) == 0 {
+		return fmt.Errorf("%s %s needs at least one value", c.Subject.Name, c.Operator)
+	}
+	for i, value := range c.Values {
+		switch a.kind {
+		case percentAttribute:
+			if value.Kind != NumberValue || value.Number < 0 || value.Number > 100 {
+				return fmt.Errorf("percent is compared with numbers between 0 and 100, found %s", value)
+			}
+		case timeAttribute:
+			if value.Kind == StringValue {
+				t, err := parseTime(value.Text, "UTC")
+				if err != nil {
+					return err
+				}
+				c.Values[i] = Value{Kind: DateTimeValue, Text: value.Text, Time: t, Zone: "UTC"}
+			} else if value.Kind != DateTimeValue {
+				return fmt.Errorf("%s is compared with dates, found %s", c.Subject.Name, value)
+			}
+		default:
+			if value.Kind == DateTimeValue {
+				return fmt.Errorf("%s cannot be compared with a date", c.Subject.Name)
+			}
+		}
+		if c.Operator == "matches" {
+			if _, err := regexp.Compile(value.Text); err != nil {
+				return fmt.Errorf("invalid regular expression %s", value)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Literal) Eval(ctx *Context) (bool, error) {
+	return l.Value, nil
+}
+
+func (n *Not) Eval(ctx *Context) (bool, error) {
+	x, err := n.X.Eval(ctx)
+	return !x, err
+}
+
+func (l *Logical) Eval(ctx *Context) (bool, error) {
+	left, err := l.Left.Eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	if (l.Operator == "&&" && !left) || (l.Operator == "||" && left) {
+		return left, nil
+	}
+	return l.Right.Eval(ctx)
+}
+
+func (c *Comparison) Eval(ctx *Context) (bool, error) {
+	attr, ok := attributes[c.Subject.Name]
+	if !ok {
+		return false, nil
+	}
+	switch attr.kind {
+	case percentAttribute:
+		if ctx.InstallationID == "" {
+			return false, nil
+		}
+		percent := Percentile(c.Subject.Seed, ctx.InstallationID)
+		if c.Operator == "between" {
+			return percent > c.Values[0].Number && percent <= c.Values[1].Number, nil
+		}
+		return compareResult(c.Operator, compareFloats(percent, c.Values[0].Number)), nil
+	case timeAttribute:
+		t := ctx.FirstOpen
+		if c.Subject.Name == "dateTime" {
+			t = ctx.Now
+			if t.IsZero() {
+				t = time.Now()
+			}
+		}
+		if t.IsZero() {
+			return false, nil
+		}
+		return compareResult(c.Operator, compareTimes(t, c.Values[0].Time)), nil
+	case audienceAttribute:
+		matched := 0
+		for _, value := range c.Values {
+			for _, audience := range ctx.Audiences {
+				if audience == value.Text {
+					matched++
+					break
+				}
+			}
+		}
+		switch c.Operator {
+		case "inAtLeastOne":
+			return matched > 0, nil
+		case "inAll":
+			return matched == len(c.Values), nil
+		case "notInAtLeastOne":
+			return matched < len(c.Values), nil
+		}
+		return matched == 0, nil
+	}
+	actual := c.Subject.lookup(ctx)
+	if actual == "" {
+		return false, nil
+	}
+	return compareText(attr.kind, actual, c.Operator, c.Values)
+}
+
+func (s Subject) lookup(ctx *Context) string {
+	switch s.Name {
+	case "app.id":
+		return ctx.AppID
+	case "app.version":
+		return ctx.AppVersion
+	case "app.build":
+		return ctx.AppBuild
+	case "app.firebaseInstallationId":
+		return ctx.InstallationID
+	case "app.userProperty":
+		return ctx.UserProperties[s.Key]
+	case "app.customSignal":
+		return ctx.CustomSignals[s.Key]
+	case "device.os":
+		return ctx.Platform
+	case "device.language":
+		return ctx.Language
+	case "device.country":
+		return ctx.Country
+	}
+	return ""
+}
+
+func compareText(kind attributeKind, actual, operator string, values []Value) (bool, error) {
+	anyMatch := func(match func(Value) bool) bool {
+		for _, value := range values {
+			if match(value) {
+				return true
+			}
+		}
+		return false
+	}
+	equal := func(value Value) bool {
+		return compareStrings(kind, actual, value.Text) == 0
+	}
+	switch operator {
+	case "==", "in", "exactlyMatches":
+		return anyMatch(equal), nil
+	case "!=":
+		return !anyMatch(equal), nil
+	case "contains":
+		return anyMatch(func(value Value) bool { return strings.Contains(actual, value.Text) }), nil
+	case "notContains":
+		return !anyMatch(func(value Value) bool { return strings.Contains(actual, value.Text) }), nil
+	case "startsWith":
+		return anyMatch(func(value Value) bool { return strings.HasPrefix(actual, value.Text) }), nil
+	case "matches":
+		for _, value := range values {
+			matched, err := regexp.MatchString(value.Text, actual)
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	}
+	return compareResult(operator, compareStrings(kind, actual, values[0].Text)), nil
+}
+
+// compareStrings compares versions segment by segment, numbers numerically, device attributes ignoring case and
+// everything else as plain strings.
+func compareStrings(kind attributeKind, a, b string) int {
+	if kind == versionAttribute {
+		return compareVersions(a, b)
+	}
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return compareFloats(x, y)
+	}
+	if kind == textAttribute {
+		a, b = strings.ToLower(a), strings.ToLower(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func compareVersions(a, b string) int {
+	x, y := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(x) || i < len(y); i++ {
+		p, q := "0", "0"
+		if i < len(x) {
+			p = x[i]
+		}
+		if i < len(y) {
+			q = y[i]
+		}
+		m, errP := strconv.ParseInt(p, 10, 64)
+		n, errQ := strconv.ParseInt(q, 10, 64)
+		result := 0
+		if errP == nil && errQ == nil {
+			result = compareFloats(float64(m), float64(n))
+		} else {
+			result = strings.Compare(p, q)
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func compareResult(operator string, result int) bool {
+	switch operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// Percentile places an installation in [0, 100) for percent conditions. It is a stable local approximation: the same
+// seed and installation always get the same percentile, so a device stays in or out of a rollout across local
+// evaluations, but the percentile differs from the one Firebase assigns to that installation.
+func Percentile(seed, installationID string) float64 {
+	input := installationID
+	if seed != "" {
+		input = seed + "." + installationID
+	}
+	sum := sha256.Sum256([]byte(input))
+	return float64(binary.BigEndian.Uint64(sum[:8])%100000000) / 1000000
+}
@@ -0,0 +1,91 @@
+package condition
+
+import "time"
+
+// tristate is the value of an expression that depends on unknown device attributes.
+type tristate int
+
+const (
+	unknown tristate = iota
+	alwaysFalse
+	alwaysTrue
+)
+
+// NeverMatchesAfter tells whether expr evaluates to false for every device at every time from t on, judging only
+// by its dateTime comparisons. An expression like dateTime < dateTime('2021-08-31T00:00:00') never matches again once
+// that date has passed, whatever the other attributes of the device.
+func NeverMatchesAfter(expr Expression, t time.Time) bool {
+	return evalAfter(expr, t) == alwaysFalse
+}
+
+// AlwaysMatchesAfter tells whether expr evaluates to true for every device at every time from t on.
+func AlwaysMatchesAfter(expr Expression, t time.Time) bool {
+	return evalAfter(expr, t) == alwaysTrue
+}
+
+func evalAfter(expr Expression, t time.Time) tristate {
+	switch e := expr.(type) {
+	case *Literal:
+		if e.Value {
+			return alwaysTrue
+		}
+		return alwaysFalse
+	case *Not:
+		switch evalAfter(e.X, t) {
+		case alwaysTrue:
+			return alwaysFalse
+		case alwaysFalse:
+			return alwaysTrue
+		}
+		return unknown
+	case *Logical:
+		left, right := evalAfter(e.Left, t), evalAfter(e.Right, t)
+		if e.Operator == "&&" {
+			switch {
+			case left == alwaysFalse || right == alwaysFalse:
+				return alwaysFalse
+			case left == alwaysTrue && right == alwaysTrue:
+				return alwaysTrue
+			}
+			return unknown
+		}
+		switch {
+		case left == alwaysTrue || right == alwaysTrue:
+			return alwaysTrue
+		case left == alwaysFalse && right == alwaysFalse:
+			return alwaysFalse
+		}
+		return unknown
+	case *Comparison:
+		return comparisonAfter(e, t)
+	}
+	return unknown
+}
+
+// comparisonAfter decides a dateTime comparison for all times from t on. The current time only grows, so a
+// comparison with a date that has passed has the same result forever.
+func comparisonAfter(c *Comparison, t time.Time) tristate {
+	if c.Subject.Name != "dateTime" || len(c.Values) != 1 || c.Values[0].Kind != DateTimeValue {
+		return unknown
+	}
+	limit := c.Values[0].Time
+	switch c.Operator {
+	case "<":
+		if !limit.After(t) {
+			return alwaysFalse
+		}
+	case "<=", "==":
+		if limit.Before(t) {
+			return alwaysFalse
+		}
+	case ">=":
+		if !limit.After(t) {
+			return alwaysTrue
+		}
+	case ">", "!=":
+		if limit.Before(t) {
+			return alwaysTrue
+		}
+	}
+	return unknown
+}
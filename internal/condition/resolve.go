@@ -0,0 +1,43 @@
+package condition
+
+import (
+	"fmt"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+// Resolve returns the value every parameter of config takes for ctx. A parameter takes the value of the first
+// condition, in the order of config.Conditions, that matches and that the parameter has a value for, and its default
+// value otherwise. Parameters resolving to the in-app default are left out, as the client falls back to its own
+// default for missing keys.
+func Resolve(config *model.Config, ctx *Context) (map[string]string, error) {
+	matched := make([]string, 0, len(config.Conditions))
+	for _, c := range config.Conditions {
+		expr, err := Parse(c.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for condition %s: %s", c.Name, err.Error())
+		}
+		ok, err := expr.Eval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error evaluating condition %s: %s", c.Name, err.Error())
+		}
+		if ok {
+			matched = append(matched, c.Name)
+		}
+	}
+	entries := map[string]string{}
+	for key, parameter := range config.Parameters {
+		value := parameter.DefaultValue
+		for _, name := range matched {
+			if conditional, ok := parameter.ConditionalValues[name]; ok {
+				value = &conditional
+				break
+			}
+		}
+		if value == nil || value.UseInAppDefault {
+			continue
+		}
+		entries[key] = value.ExplicitValue
+	}
+	return entries, nil
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,6 +29,61 @@ func (c *ConfigTestSuite) Test_Config_env_presnt() {
 	assert.NoError(c.T(), err)
 }
 
+func (c *ConfigTestSuite) Test_ToolConfig() {
+	dir, err := ioutil.TempDir("", "tool-config")
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	toolConfig, err := LoadToolConfig(dir)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "\t", toolConfig.Format.Indent)
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"format":{"indent":"  ","sortKeys":true}}`), 0644)
+	toolConfig, err = LoadToolConfig(dir)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "  ", toolConfig.Format.Indent)
+	assert.True(c.T(), toolConfig.Format.SortKeys)
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"format":{"indent":"x"}}`), 0644)
+	_, err = LoadToolConfig(dir)
+	assert.Error(c.T(), err)
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"lint":{"rules":{"json-depth":{"severity":"warn","max":3}}}}`), 0644)
+	toolConfig, err = LoadToolConfig(dir)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), LintRule{Severity: SeverityWarn, Max: 3}, toolConfig.Lint.Rules["json-depth"])
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"lint":{"rules":{"json-depth":{"severity":"fatal"}}}}`), 0644)
+	_, err = LoadToolConfig(dir)
+	assert.EqualError(c.T(), err, "invalid firebase-ctl.json: lint.rules.json-depth.severity must be one of off, warn and error")
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"notifications":[{"url":"$HOOK_URL"},{"url":"https://example.com","format":"email"}]}`), 0644)
+	_, err = LoadToolConfig(dir)
+	assert.EqualError(c.T(), err, "invalid firebase-ctl.json: notifications[1].format must be one of slack and generic")
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"target":{"credentials":"$EU_CREDENTIALS","stage":2}}`), 0644)
+	toolConfig, err = LoadToolConfig(dir)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), Target{Credentials: "$EU_CREDENTIALS", Stage: 2}, toolConfig.Target)
+
+	ioutil.WriteFile(filepath.Join(dir, ToolConfigFile), []byte(`{"target":{"stage":-1}}`), 0644)
+	_, err = LoadToolConfig(dir)
+	assert.EqualError(c.T(), err, "invalid firebase-ctl.json: target.stage cannot be negative")
+}
+
+func (c *ConfigTestSuite) Test_AuditLogPath() {
+	toolConfig := DefaultToolConfig()
+	assert.Equal(c.T(), filepath.Join("config", StateDir, AuditLogFile), toolConfig.AuditLogPath("config"))
+	toolConfig.Audit.Path = "logs/audit.jsonl"
+	assert.Equal(c.T(), filepath.Join("config", "logs", "audit.jsonl"), toolConfig.AuditLogPath("config"))
+	os.Setenv("AUDIT_TEST_DIR", "/var/log")
+	defer os.Unsetenv("AUDIT_TEST_DIR")
+	toolConfig.Audit.Path = "$AUDIT_TEST_DIR/firebase-ctl.jsonl"
+	assert.Equal(c.T(), "/var/log/firebase-ctl.jsonl", toolConfig.AuditLogPath("config"))
+}
+
 func Test_Suite(t *testing.T) {
 	suite.Run(t, new(ConfigTestSuite))
 }
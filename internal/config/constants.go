@@ -6,3 +6,9 @@ const ParametersDir = "parameters"
 const SecretParametersDir = "secret-parameters"
 const ConditionsFile = "conditions.json"
 const ParametersFile = "parameters.json"
+const ConditionsOrderFile = "order.json"
+const SecretParameterPrefix = "SEC_"
+const StateDir = ".firebase-ctl"
+const LastPullFile = "last-pull.json"
+const ToolConfigFile = "firebase-ctl.json"
+const AuditLogFile = "audit.jsonl"
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ToolConfig holds the settings of firebase-ctl for a source directory. It is read from ToolConfigFile
+// in the root of the directory, and every setting has a default when the file does not exist.
+type ToolConfig struct {
+	Format FormatConfig `json:"format"`
+	// Namespaces restricts diff and apply to the parameters owned by this source directory.
+	// All parameters are owned when it is empty.
+	Namespaces []Namespace `json:"namespaces"`
+	Apply      ApplyConfig `json:"apply"`
+	Lint       LintConfig  `json:"lint"`
+	// Notifications are the webhooks called after every successful apply.
+	Notifications []Notification `json:"notifications"`
+	Audit         AuditConfig    `json:"audit"`
+	Target        Target         `json:"target"`
+}
+
+// Target is the Remote Config project a source directory is applied to, for commands run on several directories at
+// once. Fields left empty fall back to the command line flags and GOOGLE_APPLICATION_CREDENTIALS.
+type Target struct {
+	// Credentials is the path of the service account file of the project. Environment variables in it are expanded.
+	Credentials string `json:"credentials,omitempty"`
+	// Endpoint is the base url of a Remote Config REST endpoint to use instead of Firebase, and ProjectID the project
+	// used with it.
+	Endpoint  string `json:"endpoint,omitempty"`
+	ProjectID string `json:"projectId,omitempty"`
+	// Stage orders applies across directories: a directory is applied once every directory of a lower stage was
+	// applied successfully. Stages start at 1, directories that set none are applied one at a time.
+	Stage int `json:"stage,omitempty"`
+}
+
+// AuditConfig locates the audit log, which records every template version published from the source directory.
+type AuditConfig struct {
+	// Path of the log, relative to the source directory unless absolute. Environment variables in it are expanded.
+	// Defaults to AuditLogFile in StateDir.
+	Path string `json:"path,omitempty"`
+}
+
+// AuditLogPath returns the path of the audit log of the source directory dir.
+func (t *ToolConfig) AuditLogPath(dir string) string {
+	path := os.ExpandEnv(t.Audit.Path)
+	if path == "" {
+		return filepath.Join(dir, StateDir, AuditLogFile)
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Notification formats.
+const (
+	NotificationSlack   = "slack"
+	NotificationGeneric = "generic"
+)
+
+// Notification is a webhook called after an apply. The slack format posts {"text": message} as Slack incoming
+// webhooks and compatible chat tools expect, the generic format posts a JSON document describing the apply.
+type Notification struct {
+	// URL of the webhook. Environment variables in it are expanded, so that secret urls can stay out of the file.
+	URL string `json:"url"`
+	// Format is slack or generic. Defaults to slack.
+	Format string `json:"format,omitempty"`
+	// Template is a Go text/template rendering the message of the slack format, or the whole body of the generic
+	// format. Defaults to a summary of the changes.
+	Template string `json:"template,omitempty"`
+}
+
+// Lint rule severities. Rules are off unless configured.
+const (
+	SeverityOff   = "off"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// LintConfig enables the lint rules run by validate, keyed by rule name.
+type LintConfig struct {
+	Rules map[string]LintRule `json:"rules"`
+}
+
+// LintRule configures a single lint rule. Only the options the rule uses are read.
+type LintRule struct {
+	Severity string `json:"severity"`
+	// Pattern is the regular expression of the key-naming rule.
+	Pattern string `json:"pattern,omitempty"`
+	// Max is the limit of the max-value-size, max-conditions and json-depth rules.
+	Max int `json:"max,omitempty"`
+	// Characters are the characters banned by the banned-characters rule.
+	Characters string `json:"characters,omitempty"`
+}
+
+// ApplyConfig holds the safeguards of apply.
+type ApplyConfig struct {
+	// MaxChanges is the maximum number of parameters and conditions a single apply may change, unlimited when 0.
+	MaxChanges int `json:"maxChanges"`
+}
+
+// FormatConfig controls how configuration files are serialized.
+type FormatConfig struct {
+	// Indent is the whitespace used for one level of indentation. Defaults to a tab.
+	Indent string `json:"indent"`
+	// SortKeys orders the parameters of a file by key instead of keeping their order in the file.
+	SortKeys bool `json:"sortKeys"`
+	// PrettyJsonValues indents the values of json parameters.
+	PrettyJsonValues bool `json:"prettyJsonValues"`
+}
+
+// Namespace is a set of parameters owned by a source directory, either every key starting with Prefix
+// or every parameter of the parameter group Group.
+type Namespace struct {
+	Prefix string `json:"prefix,omitempty"`
+	Group  string `json:"group,omitempty"`
+}
+
+func DefaultToolConfig() *ToolConfig {
+	return &ToolConfig{
+		Format: FormatConfig{Indent: "\t"},
+	}
+}
+
+func LoadToolConfig(dir string) (*ToolConfig, error) {
+	toolConfig := DefaultToolConfig()
+	contents, err := ioutil.ReadFile(filepath.Join(dir, ToolConfigFile))
+	if os.IsNotExist(err) {
+		return toolConfig, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, toolConfig); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", ToolConfigFile, err.Error())
+	}
+	if err := toolConfig.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", ToolConfigFile, err.Error())
+	}
+	return toolConfig, nil
+}
+
+func (t *ToolConfig) validate() error {
+	if t.Format.Indent == "" || strings.Trim(t.Format.Indent, " \t") != "" {
+		return fmt.Errorf("format.indent must consist of spaces or tabs")
+	}
+	if t.Apply.MaxChanges < 0 {
+		return fmt.Errorf("apply.maxChanges cannot be negative")
+	}
+	for name, rule := range t.Lint.Rules {
+		switch rule.Severity {
+		case SeverityOff, SeverityWarn, SeverityError:
+		default:
+			return fmt.Errorf("lint.rules.%s.severity must be one of off, warn and error", name)
+		}
+		if rule.Max < 0 {
+			return fmt.Errorf("lint.rules.%s.max cannot be negative", name)
+		}
+	}
+	for i, notification := range t.Notifications {
+		if notification.URL == "" {
+			return fmt.Errorf("notifications[%d].url is required", i)
+		}
+		switch notification.Format {
+		case "", NotificationSlack, NotificationGeneric:
+		default:
+			return fmt.Errorf("notifications[%d].format must be one of slack and generic", i)
+		}
+	}
+	if t.Target.Stage < 0 {
+		return fmt.Errorf("target.stage cannot be negative")
+	}
+	for i, namespace := range t.Namespaces {
+		if (namespace.Prefix == "") == (namespace.Group == "") {
+			return fmt.Errorf("namespaces[%d] must set exactly one of prefix and group", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/condition"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+// FetchServer serves the client-side fetch endpoint, POST /v1/projects/<project>/namespaces/firebase:fetch, with
+// parameters resolved for the requesting device from a local config. The config can be replaced at any time with
+// Update, e.g. when its sources change.
+type FetchServer struct {
+	mu      sync.RWMutex
+	config  *model.Config
+	version int
+	now     func() time.Time
+}
+
+// FetchRequest is the request body sent by the client SDKs.
+type FetchRequest struct {
+	AppInstanceID           string            `json:"appInstanceId"`
+	AppID                   string            `json:"appId"`
+	AppVersion              string            `json:"appVersion"`
+	AppBuild                string            `json:"appBuild"`
+	CountryCode             string            `json:"countryCode"`
+	LanguageCode            string            `json:"languageCode"`
+	PlatformVersion         string            `json:"platformVersion"`
+	TimeZone                string            `json:"timeZone"`
+	SdkVersion              string            `json:"sdkVersion"`
+	PackageName             string            `json:"packageName"`
+	AnalyticsUserProperties map[string]string `json:"analyticsUserProperties"`
+	CustomSignals           map[string]string `json:"customSignals"`
+}
+
+// FetchResponse is the response body the client SDKs expect.
+type FetchResponse struct {
+	Entries         map[string]string `json:"entries,omitempty"`
+	State           string            `json:"state"`
+	TemplateVersion string            `json:"templateVersion,omitempty"`
+}
+
+func NewFetchServer() *FetchServer {
+	return &FetchServer{now: time.Now}
+}
+
+// Update replaces the config served, and bumps the template version reported to clients.
+func (s *FetchServer) Update(config *model.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	s.version++
+}
+
+func (s *FetchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 5 || parts[0] != "v1" || parts[1] != "projects" || parts[3] != "namespaces" || !strings.HasSuffix(parts[4], ":fetch") {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("unknown path %s", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "INVALID_ARGUMENT", fmt.Sprintf("%s is not supported on %s", r.Method, r.URL.Path))
+		return
+	}
+	request := FetchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("invalid fetch request: %s", err.Error()))
+		return
+	}
+	s.mu.RLock()
+	config, version := s.config, s.version
+	s.mu.RUnlock()
+	if config == nil {
+		writeJson(w, http.StatusOK, FetchResponse{State: "NO_TEMPLATE"})
+		return
+	}
+	entries, err := condition.Resolve(config, request.context(s.now()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	response := FetchResponse{Entries: entries, State: "UPDATE", TemplateVersion: strconv.Itoa(version)}
+	if len(entries) == 0 {
+		response.State = "EMPTY_CONFIG"
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (r FetchRequest) context(now time.Time) *condition.Context {
+	return &condition.Context{
+		AppID:          r.AppID,
+		AppVersion:     r.AppVersion,
+		AppBuild:       r.AppBuild,
+		Platform:       platformOf(r.AppID),
+		Language:       r.LanguageCode,
+		Country:        r.CountryCode,
+		InstallationID: r.AppInstanceID,
+		UserProperties: r.AnalyticsUserProperties,
+		CustomSignals:  r.CustomSignals,
+		Now:            now,
+	}
+}
+
+// platformOf reads the platform from a Firebase app id such as 1:1234567890:android:321abc456def7890.
+func platformOf(appID string) string {
+	parts := strings.Split(appID, ":")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
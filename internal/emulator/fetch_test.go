@@ -0,0 +1,71 @@
+package emulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FetchServerTestSuite struct {
+	suite.Suite
+	server *FetchServer
+}
+
+func TestFetchServer(t *testing.T) {
+	suite.Run(t, new(FetchServerTestSuite))
+}
+
+func (c *FetchServerTestSuite) SetupTest() {
+	c.server = NewFetchServer()
+}
+
+func (c *FetchServerTestSuite) fetch(body string) (int, FetchResponse) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/projects/local/namespaces/firebase:fetch", strings.NewReader(body))
+	c.server.ServeHTTP(recorder, request)
+	response := FetchResponse{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder.Code, response
+}
+
+func (c *FetchServerTestSuite) TestNoTemplateBeforeUpdate() {
+	code, response := c.fetch(`{}`)
+	assert.Equal(c.T(), http.StatusOK, code)
+	assert.Equal(c.T(), "NO_TEMPLATE", response.State)
+}
+
+func (c *FetchServerTestSuite) TestResolvesForDevice() {
+	c.server.Update(&model.Config{
+		Conditions: []model.Condition{
+			{Name: "ios", Expression: "device.os == 'ios'"},
+			{Name: "gold", Expression: "app.userProperty['tier'] == 'gold'"},
+		},
+		Parameters: map[string]model.Parameter{
+			"banner": {
+				DefaultValue:      &model.ParameterValue{ExplicitValue: "default"},
+				ConditionalValues: map[string]model.ParameterValue{"ios": {ExplicitValue: "ios"}, "gold": {ExplicitValue: "gold"}},
+			},
+		},
+	})
+	_, response := c.fetch(`{"appId": "1:123:ios:abc", "analyticsUserProperties": {"tier": "gold"}}`)
+	assert.Equal(c.T(), FetchResponse{Entries: map[string]string{"banner": "ios"}, State: "UPDATE", TemplateVersion: "1"}, response)
+
+	_, response = c.fetch(`{"appId": "1:123:android:abc", "analyticsUserProperties": {"tier": "gold"}}`)
+	assert.Equal(c.T(), map[string]string{"banner": "gold"}, response.Entries)
+
+	c.server.Update(&model.Config{Parameters: map[string]model.Parameter{}})
+	_, response = c.fetch(`{"appId": "1:123:android:abc"}`)
+	assert.Equal(c.T(), FetchResponse{State: "EMPTY_CONFIG", TemplateVersion: "2"}, response)
+}
+
+func (c *FetchServerTestSuite) TestInvalidConditionIsAnError() {
+	c.server.Update(&model.Config{Conditions: []model.Condition{{Name: "broken", Expression: "device.os =="}}})
+	code, _ := c.fetch(`{}`)
+	assert.Equal(c.T(), http.StatusInternalServerError, code)
+}
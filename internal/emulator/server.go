@@ -0,0 +1,347 @@
+// Package emulator implements the Remote Config REST API on top of local files, so that firebase-ctl and its
+// tests can run without a Firebase project.
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/spf13/afero"
+)
+
+const versionsDir = "versions"
+
+// projectPattern matches the project ids accepted, which are used as directory names.
+var projectPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// Server serves the Remote Config REST endpoints for any number of projects. Every published version of a
+// project is stored in dataDir/<project>/versions/<version number>.json.
+type Server struct {
+	fs      afero.Fs
+	dataDir string
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+func NewServer(fs afero.Fs, dataDir string) *Server {
+	return &Server{fs: fs, dataDir: dataDir, now: time.Now}
+}
+
+type apiError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /v1/projects/<project>/remoteConfig[:method]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "v1" || parts[1] != "projects" || !strings.HasPrefix(parts[3], "remoteConfig") {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("unknown path %s", r.URL.Path))
+		return
+	}
+	project, method := parts[2], strings.TrimPrefix(parts[3], "remoteConfig")
+	if !projectPattern.MatchString(project) {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("invalid project id %q", project))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case method == "" && r.Method == http.MethodGet:
+		s.getTemplate(w, r, project)
+	case method == "" && r.Method == http.MethodPut:
+		s.publishTemplate(w, r, project)
+	case method == ":listVersions" && r.Method == http.MethodGet:
+		s.listVersions(w, r, project)
+	case method == ":rollback" && r.Method == http.MethodPost:
+		s.rollback(w, r, project)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "INVALID_ARGUMENT", fmt.Sprintf("%s is not supported on %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) getTemplate(w http.ResponseWriter, r *http.Request, project string) {
+	latest, err := s.latestVersion(project)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	versionNumber := latest
+	if requested := r.URL.Query().Get("versionNumber"); requested != "" {
+		versionNumber, err = strconv.ParseInt(requested, 10, 64)
+		if err != nil || versionNumber < 1 || versionNumber > latest {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("version %s does not exist", requested))
+			return
+		}
+	}
+	template, err := s.readVersion(project, versionNumber)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	writeTemplate(w, project, template)
+}
+
+func (s *Server) publishTemplate(w http.ResponseWriter, r *http.Request, project string) {
+	latest, err := s.latestVersion(project)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		writeError(w, http.StatusPreconditionRequired, "FAILED_PRECONDITION", "If-Match header is required")
+		return
+	}
+	if ifMatch != "*" && ifMatch != etag(project, latest) {
+		writeError(w, http.StatusPreconditionFailed, "FAILED_PRECONDITION", "the template was modified since it was read")
+		return
+	}
+	template := &remoteconfig.RemoteConfig{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, template)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("invalid template: %s", err.Error()))
+		return
+	}
+	if err := Validate(*template); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	updateType := "INCREMENTAL_UPDATE"
+	if ifMatch == "*" {
+		updateType = "FORCED_UPDATE"
+	}
+	template.Version = s.newVersion(latest+1, template.Version.Description, updateType, 0)
+	if r.URL.Query().Get("validateOnly") == "true" {
+		template.Version = remoteconfig.Version{}
+		writeTemplateWithEtag(w, etag(project, latest), template)
+		return
+	}
+	if err := s.writeVersion(project, template); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	writeTemplate(w, project, template)
+}
+
+func (s *Server) listVersions(w http.ResponseWriter, r *http.Request, project string) {
+	latest, err := s.latestVersion(project)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize <= 0 || pageSize > 300 {
+		pageSize = 300
+	}
+	start := latest
+	if token := r.URL.Query().Get("pageToken"); token != "" {
+		start, err = strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid page token")
+			return
+		}
+	}
+	if end := r.URL.Query().Get("endVersionNumber"); end != "" {
+		endVersion, err := strconv.ParseInt(end, 10, 64)
+		if err == nil && endVersion < start {
+			start = endVersion
+		}
+	}
+	response := remoteconfig.ListVersionsResponse{Versions: []remoteconfig.Version{}}
+	for n := start; n >= 1; n-- {
+		if len(response.Versions) == pageSize {
+			response.NextPageToken = strconv.FormatInt(n, 10)
+			break
+		}
+		template, err := s.readVersion(project, n)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+			return
+		}
+		response.Versions = append(response.Versions, template.Version)
+	}
+	writeJson(w, http.StatusOK, response)
+}
+
+func (s *Server) rollback(w http.ResponseWriter, r *http.Request, project string) {
+	request := struct {
+		VersionNumber string `json:"versionNumber"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	latest, err := s.latestVersion(project)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	versionNumber, err := strconv.ParseInt(request.VersionNumber, 10, 64)
+	if err != nil || versionNumber < 1 || versionNumber > latest {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("version %s does not exist", request.VersionNumber))
+		return
+	}
+	template, err := s.readVersion(project, versionNumber)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	template.Version = s.newVersion(latest+1, fmt.Sprintf("Rollback to version %d", versionNumber), "ROLLBACK", versionNumber)
+	if err := s.writeVersion(project, template); err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL", err.Error())
+		return
+	}
+	writeTemplate(w, project, template)
+}
+
+func (s *Server) newVersion(number int64, description, updateType string, rollbackSource int64) remoteconfig.Version {
+	return remoteconfig.Version{
+		Description:    description,
+		RollbackSource: rollbackSource,
+		UpdateOrigin:   "REST_API",
+		UpdateTime:     s.now().UTC(),
+		UpdateType:     updateType,
+		UpdateUser:     &remoteconfig.User{Email: "emulator@localhost", Name: "emulator"},
+		VersionNumber:  number,
+	}
+}
+
+func (s *Server) latestVersion(project string) (int64, error) {
+	dir := filepath.Join(s.dataDir, project, versionsDir)
+	if _, err := s.fs.Stat(dir); err != nil {
+		return 0, nil
+	}
+	infos, err := afero.ReadDir(s.fs, dir)
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, info := range infos {
+		n, err := strconv.ParseInt(strings.TrimSuffix(info.Name(), ".json"), 10, 64)
+		if err == nil && n > latest {
+			latest = n
+		}
+	}
+	return latest, nil
+}
+
+// readVersion returns the template of a version, or an empty template for version 0.
+func (s *Server) readVersion(project string, versionNumber int64) (*remoteconfig.RemoteConfig, error) {
+	template := &remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{},
+		Parameters: map[string]remoteconfig.Parameter{},
+	}
+	if versionNumber == 0 {
+		return template, nil
+	}
+	contents, err := afero.ReadFile(s.fs, s.versionPath(project, versionNumber))
+	if err != nil {
+		return nil, err
+	}
+	return template, json.Unmarshal(contents, template)
+}
+
+func (s *Server) writeVersion(project string, template *remoteconfig.RemoteConfig) error {
+	path := s.versionPath(project, template.Version.VersionNumber)
+	if err := s.fs.MkdirAll(filepath.Dir(path), 0744); err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(template, "", "\t")
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(s.fs, path, contents, 0644)
+}
+
+func (s *Server) versionPath(project string, versionNumber int64) string {
+	return filepath.Join(s.dataDir, project, versionsDir, fmt.Sprintf("%d.json", versionNumber))
+}
+
+// Validate performs the structural checks the Remote Config API applies to a published template.
+func Validate(template remoteconfig.RemoteConfig) error {
+	var problems []string
+	conditions := map[string]bool{}
+	for _, condition := range template.Conditions {
+		if condition.Name == "" || condition.Expression == "" {
+			problems = append(problems, "conditions need a name and an expression")
+			continue
+		}
+		if conditions[condition.Name] {
+			problems = append(problems, fmt.Sprintf("duplicate condition %s", condition.Name))
+		}
+		conditions[condition.Name] = true
+	}
+	parameters := map[string]remoteconfig.Parameter{}
+	for key, parameter := range template.Parameters {
+		parameters[key] = parameter
+	}
+	for name, group := range template.ParameterGroups {
+		for key, parameter := range group.Parameters {
+			if _, ok := parameters[key]; ok {
+				problems = append(problems, fmt.Sprintf("parameter %s of group %s is defined more than once", key, name))
+			}
+			if parameter != nil {
+				parameters[key] = *parameter
+			}
+		}
+	}
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for name := range parameters[key].ConditionalValues {
+			if !conditions[name] {
+				problems = append(problems, fmt.Sprintf("parameter %s uses undefined condition %s", key, name))
+			}
+		}
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid template: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func etag(project string, versionNumber int64) string {
+	return fmt.Sprintf("etag-%s-%d", project, versionNumber)
+}
+
+func writeTemplate(w http.ResponseWriter, project string, template *remoteconfig.RemoteConfig) {
+	writeTemplateWithEtag(w, etag(project, template.Version.VersionNumber), template)
+}
+
+func writeTemplateWithEtag(w http.ResponseWriter, etag string, template *remoteconfig.RemoteConfig) {
+	w.Header().Set("ETag", etag)
+	writeJson(w, http.StatusOK, template)
+}
+
+func writeError(w http.ResponseWriter, code int, status, message string) {
+	body := apiError{}
+	body.Error.Code, body.Error.Status, body.Error.Message = code, status, message
+	writeJson(w, code, body)
+}
+
+func writeJson(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,150 @@
+package emulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	server *Server
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (c *ServerTestSuite) SetupTest() {
+	c.server = NewServer(afero.NewMemMapFs(), "/data")
+	c.server.now = func() time.Time { return time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC) }
+}
+
+func (c *ServerTestSuite) do(method, target, ifMatch, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if ifMatch != "" {
+		request.Header.Set("If-Match", ifMatch)
+	}
+	c.server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (c *ServerTestSuite) template(recorder *httptest.ResponseRecorder) remoteconfig.RemoteConfig {
+	template := remoteconfig.RemoteConfig{}
+	assert.NoError(c.T(), json.Unmarshal(recorder.Body.Bytes(), &template))
+	return template
+}
+
+func (c *ServerTestSuite) publish(ifMatch, value string) *httptest.ResponseRecorder {
+	return c.do(http.MethodPut, "/v1/projects/local/remoteConfig", ifMatch,
+		`{"parameters": {"banner": {"defaultValue": {"value": "`+value+`"}}}, "version": {"description": "`+value+`"}}`)
+}
+
+func (c *ServerTestSuite) TestGetBeforePublishIsEmpty() {
+	recorder := c.do(http.MethodGet, "/v1/projects/local/remoteConfig", "", "")
+	assert.Equal(c.T(), http.StatusOK, recorder.Code)
+	assert.Equal(c.T(), "etag-local-0", recorder.Header().Get("ETag"))
+	assert.Empty(c.T(), c.template(recorder).Parameters)
+}
+
+func (c *ServerTestSuite) TestPublishChecksTheEtag() {
+	assert.Equal(c.T(), http.StatusPreconditionRequired, c.publish("", "a").Code)
+
+	recorder := c.publish("etag-local-0", "a")
+	assert.Equal(c.T(), http.StatusOK, recorder.Code)
+	assert.Equal(c.T(), "etag-local-1", recorder.Header().Get("ETag"))
+	assert.Equal(c.T(), "INCREMENTAL_UPDATE", c.template(recorder).Version.UpdateType)
+
+	assert.Equal(c.T(), http.StatusPreconditionFailed, c.publish("etag-local-0", "b").Code, "a stale etag should be rejected")
+	recorder = c.publish("*", "b")
+	assert.Equal(c.T(), http.StatusOK, recorder.Code)
+	assert.Equal(c.T(), "FORCED_UPDATE", c.template(recorder).Version.UpdateType)
+	assert.Equal(c.T(), int64(2), c.template(recorder).Version.VersionNumber)
+}
+
+func (c *ServerTestSuite) TestPublishRejectsInvalidTemplates() {
+	recorder := c.do(http.MethodPut, "/v1/projects/local/remoteConfig", "*",
+		`{"parameters": {"banner": {"defaultValue": {"value": "a"}, "conditionalValues": {"ios": {"value": "b"}}}}}`)
+	assert.Equal(c.T(), http.StatusBadRequest, recorder.Code)
+	assert.Contains(c.T(), recorder.Body.String(), "parameter banner uses undefined condition ios")
+}
+
+func (c *ServerTestSuite) TestValidateOnlyDoesNotPublish() {
+	recorder := c.do(http.MethodPut, "/v1/projects/local/remoteConfig?validateOnly=true", "*", `{"parameters": {}}`)
+	assert.Equal(c.T(), http.StatusOK, recorder.Code)
+	recorder = c.do(http.MethodGet, "/v1/projects/local/remoteConfig", "", "")
+	assert.Equal(c.T(), "etag-local-0", recorder.Header().Get("ETag"))
+}
+
+func (c *ServerTestSuite) TestGetVersionAndListVersions() {
+	c.publish("*", "a")
+	c.publish("*", "b")
+	c.publish("*", "c")
+
+	recorder := c.do(http.MethodGet, "/v1/projects/local/remoteConfig?versionNumber=2", "", "")
+	assert.Equal(c.T(), "b", c.template(recorder).Parameters["banner"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), http.StatusNotFound, c.do(http.MethodGet, "/v1/projects/local/remoteConfig?versionNumber=4", "", "").Code)
+
+	recorder = c.do(http.MethodGet, "/v1/projects/local/remoteConfig:listVersions?pageSize=2", "", "")
+	response := remoteconfig.ListVersionsResponse{}
+	assert.NoError(c.T(), json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(c.T(), []int64{3, 2}, versionNumbers(response.Versions))
+	assert.Equal(c.T(), "1", response.NextPageToken)
+
+	recorder = c.do(http.MethodGet, "/v1/projects/local/remoteConfig:listVersions?pageSize=2&pageToken=1", "", "")
+	response = remoteconfig.ListVersionsResponse{}
+	assert.NoError(c.T(), json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(c.T(), []int64{1}, versionNumbers(response.Versions))
+	assert.Empty(c.T(), response.NextPageToken)
+}
+
+func (c *ServerTestSuite) TestRollbackPublishesAnOldVersion() {
+	c.publish("*", "a")
+	c.publish("*", "b")
+
+	recorder := c.do(http.MethodPost, "/v1/projects/local/remoteConfig:rollback", "", `{"versionNumber": "1"}`)
+	assert.Equal(c.T(), http.StatusOK, recorder.Code)
+	rolledBack := c.template(recorder)
+	assert.Equal(c.T(), "a", rolledBack.Parameters["banner"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), int64(3), rolledBack.Version.VersionNumber)
+	assert.Equal(c.T(), int64(1), rolledBack.Version.RollbackSource)
+	assert.Equal(c.T(), "ROLLBACK", rolledBack.Version.UpdateType)
+
+	recorder = c.do(http.MethodGet, "/v1/projects/local/remoteConfig", "", "")
+	assert.Equal(c.T(), "a", c.template(recorder).Parameters["banner"].DefaultValue.ExplicitValue)
+
+	recorder = c.do(http.MethodPost, "/v1/projects/local/remoteConfig:rollback", "", `{"versionNumber": "7"}`)
+	assert.Equal(c.T(), http.StatusNotFound, recorder.Code)
+}
+
+func (c *ServerTestSuite) TestUnknownPaths() {
+	assert.Equal(c.T(), http.StatusNotFound, c.do(http.MethodGet, "/v1/projects/local", "", "").Code)
+	assert.Equal(c.T(), http.StatusMethodNotAllowed, c.do(http.MethodDelete, "/v1/projects/local/remoteConfig", "", "").Code)
+}
+
+func versionNumbers(versions []remoteconfig.Version) []int64 {
+	numbers := []int64{}
+	for _, version := range versions {
+		numbers = append(numbers, version.VersionNumber)
+	}
+	return numbers
+}
+
+func (c *ServerTestSuite) TestInvalidProjectIsRejected() {
+	for _, target := range []string{"/v1/projects/../remoteConfig", "/v1/projects/%2e%2e/remoteConfig", "/v1/projects/a.b/remoteConfig"} {
+		recorder := c.do(http.MethodPut, target, "*", `{"parameters": {}}`)
+		assert.Equal(c.T(), http.StatusBadRequest, recorder.Code, target)
+		assert.Contains(c.T(), recorder.Body.String(), "INVALID_ARGUMENT", target)
+	}
+	exists, _ := afero.Exists(c.server.fs, "/versions")
+	assert.False(c.T(), exists, "nothing should be written outside the data directory")
+}
@@ -0,0 +1,110 @@
+// Package fanout runs an operation on several targets concurrently, in stages.
+package fanout
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task is an operation on one target.
+type Task struct {
+	Name string
+	// Stage orders the tasks: every task of a stage finishes before the tasks of the next higher stage start.
+	Stage int
+}
+
+// Result is the outcome of a task.
+type Result struct {
+	Task
+	Err error
+	// Skipped tells that the task did not run, because another task failed first or the context was done.
+	Skipped  bool
+	Duration time.Duration
+}
+
+// Options controls how tasks are run.
+type Options struct {
+	// Parallel is the maximum number of tasks running at once. Values below 1 run the tasks one at a time.
+	Parallel int
+	// StopOnFailure skips the tasks that have not started when a task fails, including the ones of its stage.
+	StopOnFailure bool
+}
+
+// Run calls run for every task, with the index of the task, and returns the results in the order of tasks. Tasks that
+// have not started when ctx is done are skipped.
+func Run(ctx context.Context, tasks []Task, opts Options, run func(ctx context.Context, i int) error) []Result {
+	results := make([]Result, len(tasks))
+	for i, task := range tasks {
+		results[i] = Result{Task: task, Skipped: true}
+	}
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	mu := sync.Mutex{}
+	failed := false
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return ctx.Err() != nil || (opts.StopOnFailure && failed)
+	}
+	for _, stage := range stages(tasks) {
+		slots := make(chan struct{}, parallel)
+		wg := sync.WaitGroup{}
+		for _, i := range stage {
+			slots <- struct{}{}
+			if stopped() {
+				<-slots
+				break
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				start := time.Now()
+				err := run(ctx, i)
+				mu.Lock()
+				results[i].Err, results[i].Skipped, results[i].Duration = err, false, time.Since(start)
+				failed = failed || err != nil
+				mu.Unlock()
+			}(i)
+		}
+		wg.Wait()
+		if stopped() {
+			break
+		}
+	}
+	return results
+}
+
+// stages groups the indices of tasks by stage, in increasing stage order and in the order of tasks within a stage.
+func stages(tasks []Task) [][]int {
+	byStage := map[int][]int{}
+	numbers := []int{}
+	for i, task := range tasks {
+		if _, ok := byStage[task.Stage]; !ok {
+			numbers = append(numbers, task.Stage)
+		}
+		byStage[task.Stage] = append(byStage[task.Stage], i)
+	}
+	sort.Ints(numbers)
+	grouped := make([][]int, 0, len(numbers))
+	for _, number := range numbers {
+		grouped = append(grouped, byStage[number])
+	}
+	return grouped
+}
+
+// Failed tells whether any task failed or was skipped.
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Err != nil || result.Skipped {
+			return true
+		}
+	}
+	return false
+}
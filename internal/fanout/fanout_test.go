@@ -0,0 +1,83 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FanoutTestSuite struct {
+	suite.Suite
+}
+
+func TestFanout(t *testing.T) {
+	suite.Run(t, new(FanoutTestSuite))
+}
+
+func (c *FanoutTestSuite) TestParallelismIsBounded() {
+	tasks := make([]Task, 8)
+	mu := sync.Mutex{}
+	running, maxRunning := 0, 0
+	results := Run(context.Background(), tasks, Options{Parallel: 3}, func(ctx context.Context, i int) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	assert.Equal(c.T(), 3, maxRunning)
+	assert.False(c.T(), Failed(results))
+}
+
+func (c *FanoutTestSuite) TestStagesRunInOrderAndStopOnFailure() {
+	tasks := []Task{{Name: "us", Stage: 2}, {Name: "canary", Stage: 1}, {Name: "eu", Stage: 2}, {Name: "asia", Stage: 3}}
+	mu := sync.Mutex{}
+	order := []string{}
+	results := Run(context.Background(), tasks, Options{Parallel: 1, StopOnFailure: true}, func(ctx context.Context, i int) error {
+		mu.Lock()
+		order = append(order, tasks[i].Name)
+		mu.Unlock()
+		if tasks[i].Name == "us" {
+			return errors.New("publish failed")
+		}
+		return nil
+	})
+	assert.Equal(c.T(), []string{"canary", "us"}, order, "no task should start after a failure")
+	assert.EqualError(c.T(), results[0].Err, "publish failed")
+	assert.False(c.T(), results[1].Skipped)
+	assert.True(c.T(), results[2].Skipped, "the queued tasks of the failed stage should be skipped")
+	assert.True(c.T(), results[3].Skipped)
+	assert.Equal(c.T(), "asia", results[3].Name)
+	assert.True(c.T(), Failed(results))
+
+	results = Run(context.Background(), tasks, Options{Parallel: 2}, func(ctx context.Context, i int) error {
+		if tasks[i].Name == "us" {
+			return errors.New("publish failed")
+		}
+		return nil
+	})
+	assert.False(c.T(), results[3].Skipped)
+}
+
+func (c *FanoutTestSuite) TestCancellationSkipsPendingTasks() {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := []Task{{Name: "a"}, {Name: "b"}, {Name: "c", Stage: 1}}
+	results := Run(ctx, tasks, Options{Parallel: 1}, func(ctx context.Context, i int) error {
+		cancel()
+		return ctx.Err()
+	})
+	assert.Equal(c.T(), context.Canceled, results[0].Err)
+	assert.True(c.T(), results[1].Skipped)
+	assert.True(c.T(), results[2].Skipped)
+}
@@ -0,0 +1,35 @@
+package firebase
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Account describes who a ClientStore publishes as, and to which project.
+type Account struct {
+	Project string
+	// Identity is the email of the service account of the credentials, empty for other credentials and
+	// unauthenticated endpoints.
+	Identity string
+}
+
+// Account returns the account templates are published with.
+func (cs *ClientStore) Account() Account {
+	return cs.account
+}
+
+// readAccount returns the account of the google application credentials file at path, empty fields when unreadable.
+func readAccount(path string) Account {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Account{}
+	}
+	credentials := struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+	}{}
+	if json.Unmarshal(contents, &credentials) != nil {
+		return Account{}
+	}
+	return Account{Project: credentials.ProjectID, Identity: credentials.ClientEmail}
+}
@@ -0,0 +1,106 @@
+package firebase
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/condition"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+// ExpiredCondition is a condition whose dates have passed, along with the Parameters with values for it.
+type ExpiredCondition struct {
+	Name       string
+	Expression string
+	// File is the condition file relative to the source directory.
+	File       string
+	Parameters []string
+}
+
+// FindExpiredConditions returns the conditions in dir that cannot match at any time from now on, in template order.
+func (cs *ClientStore) FindExpiredConditions(dir string, now time.Time) ([]ExpiredCondition, error) {
+	conditionFiles, err := cs.readConditionFiles(filepath.Join(dir, config.ConditionsDir))
+	if err != nil {
+		return nil, err
+	}
+	parameterFiles, err := cs.readParameterFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	sources := parameterSources(parameterFiles)
+	expired := []ExpiredCondition{}
+	for _, file := range conditionFiles {
+		for _, c := range file.conditions {
+			expr, err := condition.Parse(c.Expression)
+			if err != nil || !condition.NeverMatchesAfter(expr, now) {
+				continue
+			}
+			found := ExpiredCondition{Name: c.Name, Expression: c.Expression, File: filepath.Join(config.ConditionsDir, file.name), Parameters: []string{}}
+			for key, relPath := range sources {
+				if _, ok := parameterFiles[relPath][key].ConditionalValues[c.Name]; ok {
+					found.Parameters = append(found.Parameters, key)
+				}
+			}
+			sort.Strings(found.Parameters)
+			expired = append(expired, found)
+		}
+	}
+	return expired, nil
+}
+
+// RemoveConditions deletes the named conditions from the sources in dir along with the conditional values for them.
+func (cs *ClientStore) RemoveConditions(dir string, names []string) error {
+	removed := map[string]bool{}
+	for _, name := range names {
+		removed[name] = true
+	}
+	conditionsDir := filepath.Join(dir, config.ConditionsDir)
+	conditionFiles, err := cs.readConditionFiles(conditionsDir)
+	if err != nil {
+		return err
+	}
+	changedConditions := []conditionFile{}
+	for _, file := range conditionFiles {
+		kept := []model.Condition{}
+		for _, c := range file.conditions {
+			if !removed[c.Name] {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) != len(file.conditions) {
+			changedConditions = append(changedConditions, conditionFile{name: file.name, conditions: kept})
+		}
+	}
+	parameterFiles, err := cs.readParameterFiles(dir)
+	if err != nil {
+		return err
+	}
+	changed := map[string]map[string]model.Parameter{}
+	for relPath, parameters := range parameterFiles {
+		for key, parameter := range parameters {
+			deleted := false
+			for name := range parameter.ConditionalValues {
+				if removed[name] {
+					delete(parameter.ConditionalValues, name)
+					deleted = true
+				}
+			}
+			if !deleted {
+				continue
+			}
+			if len(parameter.ConditionalValues) == 0 {
+				parameter.ConditionalValues = nil
+			}
+			parameters[key] = parameter
+			changed[relPath] = parameters
+		}
+	}
+	for _, file := range changedConditions {
+		if err := cs.writeJson(file.conditions, filepath.Join(conditionsDir, file.name)); err != nil {
+			return err
+		}
+	}
+	return cs.writeParameterFiles(changed, dir)
+}
@@ -0,0 +1,63 @@
+package firebase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CleanupTestSuite struct {
+	suite.Suite
+	fs  afero.Fs
+	cs  *ClientStore
+	now time.Time
+}
+
+func TestCleanup(t *testing.T) {
+	suite.Run(t, new(CleanupTestSuite))
+}
+
+func (c *CleanupTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	c.cs = &ClientStore{customFs: &customFs{fs: c.fs}}
+	c.now = time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	afero.WriteFile(c.fs, "/src/conditions/conditions.json", []byte(`[
+	{"name": "sale", "expression": "dateTime < dateTime('2021-08-31T00:00:00', 'Asia/Calcutta')", "tagColor": ""},
+	{"name": "ios", "expression": "device.os == 'ios'", "tagColor": ""}
+]`), 0644)
+	afero.WriteFile(c.fs, "/src/parameters/a.json", []byte(`{
+	"banner": {"conditionalValues": {"sale": {"value": "50% off"}}, "defaultValue": {"value": "none"}, "description": "", "valueType": "string"},
+	"theme": {"conditionalValues": {"ios": {"value": "dark"}, "sale": {"value": "red"}}, "defaultValue": {"value": "light"}, "description": "", "valueType": "string"}
+}`), 0644)
+	afero.WriteFile(c.fs, "/src/conditions/platforms.json", []byte(`[{"name": "android", "expression": "device.os == 'android'"}]`), 0644)
+	afero.WriteFile(c.fs, "/src/parameters/b.json", []byte(`{"untouched": {"defaultValue": {"value": "x"}, "valueType": "string"}}`), 0644)
+}
+
+func (c *CleanupTestSuite) TestFindExpiredConditions() {
+	expired, err := c.cs.FindExpiredConditions("/src", c.now)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []ExpiredCondition{{
+		Name:       "sale",
+		Expression: "dateTime < dateTime('2021-08-31T00:00:00', 'Asia/Calcutta')",
+		File:       "conditions/conditions.json",
+		Parameters: []string{"banner", "theme"},
+	}}, expired)
+}
+
+func (c *CleanupTestSuite) TestRemoveConditions() {
+	assert.NoError(c.T(), c.cs.RemoveConditions("/src", []string{"sale"}))
+	localConfig, err := c.cs.GetLocalConfig("/src")
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), localConfig.Conditions, 2)
+	assert.Equal(c.T(), "ios", localConfig.Conditions[0].Name)
+	assert.Nil(c.T(), localConfig.Parameters["banner"].ConditionalValues)
+	assert.Len(c.T(), localConfig.Parameters["theme"].ConditionalValues, 1)
+
+	untouched, _ := afero.ReadFile(c.fs, "/src/parameters/b.json")
+	assert.Equal(c.T(), `{"untouched": {"defaultValue": {"value": "x"}, "valueType": "string"}}`, string(untouched))
+	untouched, _ = afero.ReadFile(c.fs, "/src/conditions/platforms.json")
+	assert.Equal(c.T(), `[{"name": "android", "expression": "device.os == 'android'"}]`, string(untouched))
+}
@@ -2,9 +2,10 @@ package firebase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,132 +21,322 @@ import (
 type ClientStore struct {
 	remoteConfigClient ConfigClient
 	customFs           *customFs
+	format             config.FormatConfig
+	namespaces         []config.Namespace
+	account            Account
+	// ctx cancels the remote calls.
+	ctx context.Context
+}
+
+// requestContext returns the context of the remote calls.
+func (cs *ClientStore) requestContext() context.Context {
+	if cs.ctx == nil {
+		return context.Background()
+	}
+	return cs.ctx
+}
+
+// SetNamespaces restricts diff, validation and apply to the parameters owned by the given namespaces.
+func (cs *ClientStore) SetNamespaces(namespaces []config.Namespace) {
+	cs.namespaces = namespaces
 }
 
 func (cs *ClientStore) isRemoteEnabled() bool {
 	return cs.remoteConfigClient != nil
 }
 func (cs *ClientStore) GetLatestRemoteConfig() (*remoteconfig.RemoteConfig, error) {
-	if !cs.isRemoteEnabled() {
-		return nil, fmt.Errorf("remote client is not configured")
-	}
-	latestRemoteConfigResponse, err := cs.remoteConfigClient.GetRemoteConfig("")
+	latestRemoteConfigResponse, err := cs.getLatestRemoteResponse()
 	if err != nil {
 		return nil, err
 	}
 
 	return latestRemoteConfigResponse.RemoteConfig, err
 }
-func (cs *ClientStore) BackupRemoteConfig(rc *remoteconfig.RemoteConfig, outputDir string) error {
+
+// getLatestRemoteResponse returns the live template along with its ETag.
+func (cs *ClientStore) getLatestRemoteResponse() (*remoteconfig.Response, error) {
+	if !cs.isRemoteEnabled() {
+		return nil, fmt.Errorf("remote client is not configured")
+	}
+	return cs.remoteConfigClient.GetRemoteConfig(cs.requestContext(), "")
+}
+// BackupOptions controls the layout of the files written by BackupRemoteConfig.
+type BackupOptions struct {
+	// SplitConditionsBy is SplitByNone or SplitByPrefix.
+	SplitConditionsBy string
+	// ParameterLayout places new parameters: LayoutDefault, LayoutPrefix or LayoutGroup.
+	ParameterLayout string
+	// DefaultParametersFile is the file name used by LayoutDefault and for parameters without a group.
+	DefaultParametersFile string
+}
+
+func (opts BackupOptions) validate() error {
+	switch opts.SplitConditionsBy {
+	case "", SplitByNone, SplitByPrefix:
+	default:
+		return fmt.Errorf("unknown split mode %s", opts.SplitConditionsBy)
+	}
+	switch opts.ParameterLayout {
+	case "", LayoutDefault, LayoutPrefix, LayoutGroup:
+	default:
+		return fmt.Errorf("unknown parameter layout %s", opts.ParameterLayout)
+	}
+	return nil
+}
+
+// BackupRemoteConfig writes rc to the sources in outputDir.
+func (cs *ClientStore) BackupRemoteConfig(rc *remoteconfig.RemoteConfig, outputDir string, opts BackupOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
 	sourceDump := model.ConvertToSourceConfig(*rc)
-	for i := range sourceDump.Parameters {
-		valueType := "string"
-		if sourceDump.Parameters[i].DefaultValue != nil &&
-			(strings.HasPrefix(sourceDump.Parameters[i].DefaultValue.ExplicitValue, "{") ||
-				strings.HasPrefix(sourceDump.Parameters[i].DefaultValue.ExplicitValue, "[")) {
-			valueType = "json"
+	for _, group := range rc.ParameterGroups {
+		for key, parameter := range group.Parameters {
+			if _, ok := sourceDump.Parameters[key]; !ok && parameter != nil {
+				sourceDump.Parameters[key] = model.ConvertToSourceParameter(*parameter)
+			}
 		}
+	}
+	for i := range sourceDump.Parameters {
 		parameter := sourceDump.Parameters[i]
-		parameter.ValueType = valueType
+		parameter.ValueType = inferValueType(parameter)
 		sourceDump.Parameters[i] = parameter
 	}
-	conditionsFilePath := filepath.Join(outputDir, config.ConditionsDir, config.ConditionsFile)
-	err := cs.customFs.WriteJsonToFile(sourceDump.Conditions, conditionsFilePath)
+	parameterFiles, err := cs.layoutParameters(sourceDump.Parameters, rc, outputDir, opts)
+	if err != nil {
+		return fmt.Errorf("error reading existing parameter files: %s", err.Error())
+	}
+	conditionsDirPath := filepath.Join(outputDir, config.ConditionsDir)
+	err = cs.writeConditions(sourceDump.Conditions, conditionsDirPath, opts.SplitConditionsBy)
 	if err != nil {
 		return fmt.Errorf("error writing to conditions file: %v", err.Error())
 	}
-	parameterFilePath := filepath.Join(outputDir, config.ParametersDir, config.ParametersFile)
-	err = cs.customFs.WriteJsonToFile(sourceDump.Parameters, parameterFilePath)
+	err = cs.writeParameterFiles(parameterFiles, outputDir)
 	if err != nil {
 		return fmt.Errorf("error writing to parameter file: %s", err.Error())
 	}
 	return nil
 }
+
+func inferValueType(parameter model.Parameter) string {
+	if parameter.DefaultValue != nil &&
+		(strings.HasPrefix(parameter.DefaultValue.ExplicitValue, "{") ||
+			strings.HasPrefix(parameter.DefaultValue.ExplicitValue, "[")) {
+		return "json"
+	}
+	return "string"
+}
+// GetLocalConfig reads the sources in dir, reporting every problem found as SourceErrors.
 func (cs *ClientStore) GetLocalConfig(dir string) (*model.Config, error) {
 	remoteConfig := &model.Config{
 		Conditions:      []model.Condition{},
 		Parameters:      map[string]model.Parameter{},
 		ParameterGroups: nil,
 	}
-	conditionsFilePath := filepath.Join(dir, config.ConditionsDir, config.ConditionsFile)
-	err := cs.customFs.UnmarshalFromFile(conditionsFilePath, &(remoteConfig.Conditions))
-	if err != nil {
-		return remoteConfig, err
-	}
+	var errs SourceErrors
+	conditionsDirPath := filepath.Join(dir, config.ConditionsDir)
+	conditions, err := cs.readConditions(conditionsDirPath)
+	errs = appendSourceErrors(errs, conditionsDirPath, err)
+	remoteConfig.Conditions = conditions
 
 	parametersDirPath := filepath.Join(dir, config.ParametersDir)
-	err = cs.customFs.UnMarshalFromDir(parametersDirPath, &(remoteConfig.Parameters))
-	if err != nil {
-		return remoteConfig, err
+	if _, err := cs.customFs.fs.Stat(parametersDirPath); err != nil {
+		errs = appendSourceErrors(errs, parametersDirPath, err)
 	}
-
-	secretParametersDirPath := filepath.Join(dir, config.SecretParametersDir)
-	if _, err := os.Stat(secretParametersDirPath); !os.IsNotExist(err) {
-		err = cs.customFs.UnMarshalFromDir(secretParametersDirPath, &(remoteConfig.Parameters))
-		return remoteConfig, err
+	files, err := cs.readParameterFiles(dir)
+	errs = appendSourceErrors(errs, dir, err)
+	for key, relPath := range parameterSources(files) {
+		remoteConfig.Parameters[key] = files[relPath][key]
 	}
-	return remoteConfig, err
+	return remoteConfig, errs.orNil()
 }
-func (cs *ClientStore) pushConfigToRemote(rc remoteconfig.RemoteConfig, validateOnly bool) error {
+// pushConfigToRemote publishes rc, or only validates it, rejecting the publish when etag is set and no longer live.
+func (cs *ClientStore) pushConfigToRemote(rc remoteconfig.RemoteConfig, validateOnly bool, description, etag string) (*remoteconfig.Template, error) {
 	if !cs.isRemoteEnabled() {
-		return fmt.Errorf("remote client not implemented")
+		return nil, fmt.Errorf("remote client not implemented")
+	}
+	updateType := "FORCED_UPDATE"
+	if etag != "" {
+		updateType = "INCREMENTAL_UPDATE"
 	}
 	template := remoteconfig.Template{
+		ETag:            etag,
 		Conditions:      rc.Conditions,
 		Parameters:      rc.Parameters,
 		ParameterGroups: rc.ParameterGroups,
 		Version: remoteconfig.Version{
-			Description:    "",
+			Description:    description,
 			IsLegacy:       false,
 			RollbackSource: 0,
 			UpdateOrigin:   "REST_API",
 			UpdateTime:     time.Now(),
-			UpdateType:     "FORCED_UPDATE",
+			UpdateType:     updateType,
 			UpdateUser:     nil,
 			VersionNumber:  0,
 		},
 	}
-	_, err := cs.remoteConfigClient.PublishTemplate(context.Background(), template, validateOnly)
+	published, err := cs.remoteConfigClient.PublishTemplate(cs.requestContext(), template, validateOnly)
 	if err != nil {
-		return fmt.Errorf("error publishing template: %s ", err.Error())
+		return nil, fmt.Errorf("error publishing template: %w ", err)
 	}
-	return nil
+	return published, nil
 
 }
 func (cs *ClientStore) ValidateOnRemote(sourceConfig model.Config) error {
-	rc := sourceConfig.ToRemoteConfig()
-	return cs.pushConfigToRemote(*rc, true)
-}
-func (cs *ClientStore) ApplyConfig(sourceConfig model.Config) error {
-	rc := sourceConfig.ToRemoteConfig()
-	return cs.pushConfigToRemote(*rc, false)
-}
-func (cs *ClientStore) GetRemoteConfigDiff(inputDir string) error {
-	sourceConfig, err := cs.GetLocalConfig(inputDir)
+	rc, err := cs.templateToPublish(sourceConfig)
 	if err != nil {
 		return err
 	}
+	_, err = cs.pushConfigToRemote(*rc, true, "", "")
+	return err
+}
+
+// templateToPublish returns the template that publishing sourceConfig results in.
+func (cs *ClientStore) templateToPublish(sourceConfig model.Config) (*remoteconfig.RemoteConfig, error) {
+	if len(cs.namespaces) == 0 {
+		return sourceConfig.ToRemoteConfig(), nil
+	}
 	remoteConfig, err := cs.GetLatestRemoteConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return cs.mergeOwned(sourceConfig, *remoteConfig)
+}
 
-	convertedSourceConfig := sourceConfig.ToRemoteConfig()
-	utils.PrintDiff(*convertedSourceConfig, *remoteConfig)
-	return nil
+func (cs *ClientStore) mergeOwned(sourceConfig model.Config, remoteConfig remoteconfig.RemoteConfig) (*remoteconfig.RemoteConfig, error) {
+	if errs := utils.NewOwnership(cs.namespaces, remoteConfig).Validate(sourceConfig.Parameters); len(errs) != 0 {
+		return nil, joinErrors(errs)
+	}
+	merged, err := utils.MergeOwnedParameters(*sourceConfig.ToRemoteConfig(), remoteConfig, cs.namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return &merged, nil
 }
 
-type ConfigClient interface {
-	GetRemoteConfig(versionNumber string) (*remoteconfig.Response, error)
-	PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error)
+func joinErrors(errs []error) error {
+	sb := strings.Builder{}
+	for i := range errs {
+		sb.WriteString("\n\t" + errs[i].Error())
+	}
+	return errors.New(sb.String())
+}
+func (cs *ClientStore) mergeSelected(sourceConfig model.Config, remoteConfig remoteconfig.RemoteConfig, selection Selection) (*remoteconfig.RemoteConfig, error) {
+	remoteKeys := map[string]bool{}
+	for key := range remoteConfig.Parameters {
+		remoteKeys[key] = true
+	}
+	for _, group := range remoteConfig.ParameterGroups {
+		for key := range group.Parameters {
+			remoteKeys[key] = true
+		}
+	}
+	ownership := utils.NewOwnership(cs.namespaces, remoteConfig)
+	selected := map[string]bool{}
+	for _, key := range selection.Keys {
+		if !ownership.Owns(key) {
+			return nil, fmt.Errorf("parameter %s is outside the owned namespaces", key)
+		}
+		if _, ok := sourceConfig.Parameters[key]; !ok {
+			if !remoteKeys[key] {
+				return nil, fmt.Errorf("parameter %s exists neither locally nor on remote", key)
+			}
+			if !selection.AllowDeletes {
+				return nil, fmt.Errorf("parameter %s is only defined on remote, applying it deletes it, which needs deletes to be allowed", key)
+			}
+		}
+		selected[key] = true
+	}
+	merged, err := utils.MergeSelectedParameters(*sourceConfig.ToRemoteConfig(), remoteConfig, func(key string) bool {
+		return selected[key]
+	}, ownership.GroupForNew)
+	if err != nil {
+		return nil, err
+	}
+	return &merged, nil
 }
 
-func getFirebaseApp(ctx context.Context) (*firebase.App, error) {
-	firebaseConfig, err := config.GetFirebaseConfig()
+// ParameterKeysInFile returns the keys of the parameters defined in the file at relPath, relative to dir.
+func (cs *ClientStore) ParameterKeysInFile(dir, relPath string) ([]string, error) {
+	files, err := cs.readParameterFiles(dir)
 	if err != nil {
 		return nil, err
 	}
-	opts := option.WithCredentialsFile(firebaseConfig.Service_account_json_path)
+	parameters, ok := files[filepath.Clean(relPath)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a parameter file in %s", relPath, dir)
+	}
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// SourceFiles returns the file, relative to dir, every parameter and every condition is defined in.
+func (cs *ClientStore) SourceFiles(dir string) (parameters map[string]string, conditions map[string]string, err error) {
+	var errs SourceErrors
+	files, err := cs.readParameterFiles(dir)
+	errs = appendSourceErrors(errs, dir, err)
+	parameters = parameterSources(files)
+	conditionsDir := filepath.Join(dir, config.ConditionsDir)
+	conditionFiles, err := cs.readConditionFiles(conditionsDir)
+	errs = appendSourceErrors(errs, conditionsDir, err)
+	conditions = map[string]string{}
+	for _, file := range conditionFiles {
+		for _, c := range file.conditions {
+			conditions[c.Name] = filepath.Join(config.ConditionsDir, file.name)
+		}
+	}
+	return parameters, conditions, errs.orNil()
+}
+
+// GetRemoteConfigDiff returns the changes applying the sources in inputDir would make to the live template.
+func (cs *ClientStore) GetRemoteConfigDiff(inputDir string) (*utils.ChangeSet, error) {
+	sourceConfig, err := cs.GetLocalConfig(inputDir)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := cs.PlanApply(*sourceConfig, Selection{})
+	if err != nil {
+		return nil, err
+	}
+	return &plan.Changes, nil
+}
+
+// ListVersions returns the most recent template versions, newest first, up to limit versions or all when limit is 0.
+func (cs *ClientStore) ListVersions(limit int) ([]remoteconfig.Version, error) {
+	if !cs.isRemoteEnabled() {
+		return nil, fmt.Errorf("remote client is not configured")
+	}
+	versions := []remoteconfig.Version{}
+	options := &remoteconfig.ListVersionsOptions{PageSize: limit}
+	for {
+		response, err := cs.remoteConfigClient.ListVersions(cs.requestContext(), options)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, response.Versions...)
+		if response.NextPageToken == "" || (limit > 0 && len(versions) >= limit) {
+			break
+		}
+		options.PageToken = response.NextPageToken
+	}
+	if limit > 0 && len(versions) > limit {
+		versions = versions[:limit]
+	}
+	return versions, nil
+}
+
+type ConfigClient interface {
+	GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error)
+	PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error)
+	ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error)
+}
+
+func getFirebaseApp(ctx context.Context, credentialsFile string) (*firebase.App, error) {
+	opts := option.WithCredentialsFile(credentialsFile)
 
 	app, err := firebase.NewApp(ctx, nil, opts)
 	if err != nil {
@@ -155,14 +346,75 @@ func getFirebaseApp(ctx context.Context) (*firebase.App, error) {
 	return app, nil
 }
 
+// ClientOptions selects the Remote Config backend of a ClientStore.
+type ClientOptions struct {
+	// Endpoint is the base url of an unauthenticated Remote Config REST endpoint, such as the emulator.
+	// The Firebase project from the google application credentials is used when it is empty.
+	Endpoint string
+	// ProjectID is the project used with Endpoint.
+	ProjectID string
+	// CredentialsFile is the service account file used without Endpoint, instead of the one in
+	// GOOGLE_APPLICATION_CREDENTIALS.
+	CredentialsFile string
+	// Retry bounds the remote calls and retries the ones failing with transient errors.
+	Retry RetryOptions
+}
+
+// GetClientStoreWithOptions returns a ClientStore for the backend selected by opts, cancelled when ctx is done.
+func GetClientStoreWithOptions(ctx context.Context, opts ClientOptions) (*ClientStore, error) {
+	if opts.Endpoint == "" {
+		return getSdkClientStore(ctx, opts.CredentialsFile, opts.Retry)
+	}
+	projectID := opts.ProjectID
+	if projectID == "" {
+		projectID = DefaultEmulatorProject
+	}
+	return &ClientStore{
+		remoteConfigClient: newRetryClient(newRestClient(opts.Endpoint, projectID), opts.Retry),
+		customFs:           &customFs{afero.NewOsFs()},
+		account:            Account{Project: projectID},
+		ctx:                ctx,
+	}, nil
+}
+
+const DefaultEmulatorProject = "local"
+
+// GetClientStore returns a ClientStore for the Firebase project of the google application credentials.
 func GetClientStore(ctx context.Context) (*ClientStore, error) {
-	firebaseApp, err := getFirebaseApp(ctx)
+	return getSdkClientStore(ctx, "", RetryOptions{})
+}
+
+// getSdkClientStore returns a ClientStore for the service account in credentialsFile.
+func getSdkClientStore(ctx context.Context, credentialsFile string, retry RetryOptions) (*ClientStore, error) {
+	if credentialsFile == "" {
+		firebaseConfig, err := config.GetFirebaseConfig()
+		if err != nil {
+			return &ClientStore{remoteConfigClient: nil, customFs: &customFs{afero.NewOsFs()}}, fmt.Errorf("error creating firebase remote config app: %v", err.Error())
+		}
+		credentialsFile = firebaseConfig.Service_account_json_path
+	}
+	firebaseApp, err := getFirebaseApp(ctx, credentialsFile)
 	if err != nil {
 		return &ClientStore{remoteConfigClient: nil, customFs: &customFs{afero.NewOsFs()}} , fmt.Errorf("error creating firebase remote config app: %v", err.Error())
 	}
 	client, err := firebaseApp.RemoteConfig(ctx)
 	if err != nil {
-		return &ClientStore{remoteConfigClient: client, customFs: &customFs{afero.NewOsFs()}},  fmt.Errorf("error creating firebase remote config client: %v", err.Error())
+		return &ClientStore{remoteConfigClient: nil, customFs: &customFs{afero.NewOsFs()}},  fmt.Errorf("error creating firebase remote config client: %v", err.Error())
 	}
-	return &ClientStore{remoteConfigClient: client, customFs: &customFs{afero.NewOsFs()}}, nil
+	return &ClientStore{
+		remoteConfigClient: newRetryClient(&sdkClient{client: client}, retry),
+		customFs:           &customFs{afero.NewOsFs()},
+		account:            readAccount(credentialsFile),
+		ctx:                ctx,
+	}, nil
+}
+
+// NewLocalClientStore returns a ClientStore that works only on local files.
+func NewLocalClientStore() *ClientStore {
+	return &ClientStore{remoteConfigClient: nil, customFs: &customFs{afero.NewOsFs()}}
+}
+
+// NewLocalClientStoreWithFs returns a ClientStore that works only on the local files in fs.
+func NewLocalClientStoreWithFs(fs afero.Fs) *ClientStore {
+	return &ClientStore{remoteConfigClient: nil, customFs: &customFs{fs}}
 }
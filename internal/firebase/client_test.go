@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
 	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,7 +23,7 @@ type ClientMock struct {
 	mock.Mock
 }
 
-func (c *ClientMock) GetRemoteConfig(versionNumber string) (*remoteconfig.Response, error) {
+func (c *ClientMock) GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error) {
 	args := c.Called(versionNumber)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,6 +38,14 @@ func (c *ClientMock) PublishTemplate(ctx context.Context, template remoteconfig.
 	return args.Get(0).(*remoteconfig.Template), args.Error(1)
 }
 
+func (c *ClientMock) ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error) {
+	args := c.Called(options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*remoteconfig.ListVersionsResponse), args.Error(1)
+}
+
 func (c *ClientMock) RemoteConfig(ctx context.Context) (*remoteconfig.Client, error) {
 	args := c.Called(ctx)
 	return args.Get(0).(*remoteconfig.Client), args.Error(1)
@@ -100,7 +110,7 @@ func (c *ClientTestSuite) TestBackupToFsSuccess() {
 		Etag: "",
 	}
 	outputDir := "sample/outputdir"
-	err := cs.BackupRemoteConfig(dummyResponse.RemoteConfig, outputDir)
+	err := cs.BackupRemoteConfig(dummyResponse.RemoteConfig, outputDir, BackupOptions{})
 	assert.NoError(c.T(), err)
 	file, err := tempFs.OpenFile(filepath.Join(outputDir, "conditions", "conditions.json"), os.O_RDONLY|os.O_CREATE, 0644)
 	if err != nil {
@@ -139,34 +149,101 @@ func (c *ClientTestSuite) TestBackupToFsFailureBecauseFSErrors() {
 
 	cs.customFs.fs = tempFs
 
-	err := cs.BackupRemoteConfig(dummyResponse.RemoteConfig, outputDir)
+	err := cs.BackupRemoteConfig(dummyResponse.RemoteConfig, outputDir, BackupOptions{})
 	assert.Contains(c.T(), err.Error(), "operation not permitted")
 }
 
-func (c *ClientTestSuite) TestApplyConfig() {
+func (c *ClientTestSuite) TestPublishPlan() {
 	tempFs := afero.NewOsFs()
 	cs := ClientStore{customFs: &customFs{fs: tempFs}, remoteConfigClient: c.mock}
 	//successful publish
 	c.mock.On("PublishTemplate", context.Background(), mock.Anything, false).Return(&remoteconfig.Template{}, nil).Times(1)
-	err := cs.ApplyConfig(model.Config{
-		Conditions:      nil,
-		Parameters:      nil,
-		ParameterGroups: nil,
-	})
+	_, err := cs.PublishPlan(&Plan{})
 	assert.NoError(c.T(), err)
 	c.mock.AssertExpectations(c.T())
 
 	c.mock.On("PublishTemplate", context.Background(), mock.Anything, false).Return((*remoteconfig.Template)(nil), errors.New("test error")).Times(1)
-	err = cs.ApplyConfig(model.Config{
-		Conditions:      nil,
-		Parameters:      nil,
-		ParameterGroups: nil,
-	})
+	_, err = cs.PublishPlan(&Plan{})
 	assert.Contains(c.T(), err.Error(), "test error")
 	c.mock.AssertExpectations(c.T())
 
 }
 
+func (c *ClientTestSuite) TestPlanSelectedParameters() {
+	cs := ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}, remoteConfigClient: c.mock}
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"selected": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "remote"}},
+			"other":    {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "remote"}},
+		},
+	}}, nil)
+	c.mock.On("PublishTemplate", context.Background(), mock.MatchedBy(func(template remoteconfig.Template) bool {
+		return template.Parameters["selected"].DefaultValue.ExplicitValue == "local" &&
+			template.Parameters["other"].DefaultValue.ExplicitValue == "remote"
+	}), false).Return(&remoteconfig.Template{}, nil).Times(1)
+	sourceConfig := model.Config{Parameters: map[string]model.Parameter{
+		"selected": {DefaultValue: &model.ParameterValue{ExplicitValue: "local"}},
+		"other":    {DefaultValue: &model.ParameterValue{ExplicitValue: "local"}},
+	}}
+
+	plan, err := cs.PlanApply(sourceConfig, Selection{Keys: []string{"selected"}})
+	assert.NoError(c.T(), err)
+	_, err = cs.PublishPlan(plan)
+	assert.NoError(c.T(), err)
+	c.mock.AssertExpectations(c.T())
+
+	_, err = cs.PlanApply(sourceConfig, Selection{Keys: []string{"unknown"}})
+	assert.Contains(c.T(), err.Error(), "parameter unknown exists neither locally nor on remote")
+}
+
+func (c *ClientTestSuite) TestPlanWithNamespaces() {
+	cs := ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}, remoteConfigClient: c.mock}
+	cs.SetNamespaces([]config.Namespace{{Prefix: "payments_"}})
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"payments_limit": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "remote"}},
+			"growth_banner":  {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "remote"}},
+		},
+	}}, nil)
+	c.mock.On("PublishTemplate", context.Background(), mock.MatchedBy(func(template remoteconfig.Template) bool {
+		return template.Parameters["payments_limit"].DefaultValue.ExplicitValue == "local" &&
+			template.Parameters["growth_banner"].DefaultValue.ExplicitValue == "remote"
+	}), false).Return(&remoteconfig.Template{}, nil).Times(1)
+
+	plan, err := cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{
+		"payments_limit": {DefaultValue: &model.ParameterValue{ExplicitValue: "local"}},
+	}}, Selection{})
+	assert.NoError(c.T(), err)
+	_, err = cs.PublishPlan(plan)
+	assert.NoError(c.T(), err)
+	c.mock.AssertExpectations(c.T())
+
+	_, err = cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{
+		"growth_banner": {DefaultValue: &model.ParameterValue{ExplicitValue: "local"}},
+	}}, Selection{})
+	assert.Contains(c.T(), err.Error(), "growth_banner is outside the owned namespaces")
+}
+
+func (c *ClientTestSuite) TestListVersionsFollowsPages() {
+	cs := ClientStore{remoteConfigClient: c.mock}
+	c.mock.On("ListVersions", mock.MatchedBy(func(options *remoteconfig.ListVersionsOptions) bool {
+		return options.PageToken == ""
+	})).Return(&remoteconfig.ListVersionsResponse{
+		Versions:      []remoteconfig.Version{{VersionNumber: 3}, {VersionNumber: 2}},
+		NextPageToken: "next",
+	}, nil).Once()
+	c.mock.On("ListVersions", mock.MatchedBy(func(options *remoteconfig.ListVersionsOptions) bool {
+		return options.PageToken == "next"
+	})).Return(&remoteconfig.ListVersionsResponse{
+		Versions: []remoteconfig.Version{{VersionNumber: 1}},
+	}, nil).Once()
+
+	versions, err := cs.ListVersions(0)
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), versions, 3)
+	c.mock.AssertExpectations(c.T())
+}
+
 func (c *ClientTestSuite) TestGetDiff() {
 	tempFs := afero.NewOsFs()
 	cs := ClientStore{customFs: &customFs{fs: tempFs}, remoteConfigClient: c.mock}
@@ -178,22 +255,27 @@ func (c *ClientTestSuite) TestGetDiff() {
 	}}, nil).Times(1)
 
 	// successfully find the diff
-	err := cs.GetRemoteConfigDiff("./test")
+	changes, err := cs.GetRemoteConfigDiff("./test")
 	assert.NoError(c.T(), err)
+	assert.Len(c.T(), changes.Conditions, 2)
+	for _, change := range changes.Parameters {
+		assert.Equal(c.T(), utils.ChangeAdded, change.Type)
+		assert.NotNil(c.T(), change.After)
+	}
 	c.mock.AssertExpectations(c.T())
 
 	//pass an invalid directory
-	err = cs.GetRemoteConfigDiff("./test1")
+	_, err = cs.GetRemoteConfigDiff("./test1")
 	assert.Contains(c.T(), err.Error(), "no such file or directory")
 	c.mock.AssertExpectations(c.T())
 
 	//google api returns an error
 	c.mock.On("GetRemoteConfig", "").Return(nil, errors.New("test error")).Times(1)
-	err = cs.GetRemoteConfigDiff("./test")
+	_, err = cs.GetRemoteConfigDiff("./test")
 	assert.Contains(c.T(), err.Error(), "test error")
 	// successfully find the diff
 	c.mock.On("GetRemoteConfig", "").Return(nil, errors.New("test error")).Times(1)
-	err = cs.GetRemoteConfigDiff("./test")
+	_, err = cs.GetRemoteConfigDiff("./test")
 	assert.Contains(c.T(), err.Error(), "test error")
 	c.mock.AssertExpectations(c.T())
 
@@ -247,7 +329,7 @@ func (c *ClientTestSuite) TestBackup() {
 		remoteConfigClient: c.mock,
 		customFs:           &customFs{afero.NewMemMapFs()},
 	}
-	err := cs.BackupRemoteConfig(&configToWrite, "test")
+	err := cs.BackupRemoteConfig(&configToWrite, "test", BackupOptions{})
 	assert.NoError(c.T(), err)
 
 	localConfig, err := cs.GetLocalConfig("test")
@@ -260,3 +342,15 @@ func (c *ClientTestSuite) TestBackup() {
 func Test_Suite(t *testing.T) {
 	suite.Run(t, new(ClientTestSuite))
 }
+
+func (c *ClientTestSuite) TestReadAccount() {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "service-account.json")
+	ioutil.WriteFile(path, []byte(`{"type": "service_account", "project_id": "shop-prod", "client_email": "deploy@shop-prod.iam.gserviceaccount.com"}`), 0644)
+	assert.Equal(c.T(), Account{Project: "shop-prod", Identity: "deploy@shop-prod.iam.gserviceaccount.com"}, readAccount(path))
+	assert.Equal(c.T(), Account{}, readAccount(filepath.Join(dir, "missing.json")))
+}
@@ -0,0 +1,191 @@
+package firebase
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+const (
+	SplitByNone   = "none"
+	SplitByPrefix = "prefix"
+)
+
+// conditionFiles returns the condition files in dirPath in manifest order, or by name without a manifest.
+func (cs *ClientStore) conditionFiles(dirPath string) ([]string, error) {
+	names, err := cs.customFs.ListFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	jsonFiles := []string{}
+	hasManifest := false
+	for _, name := range names {
+		if name == config.ConditionsOrderFile {
+			hasManifest = true
+			continue
+		}
+		if filepath.Ext(name) == ".json" {
+			jsonFiles = append(jsonFiles, name)
+		}
+	}
+	if !hasManifest {
+		return jsonFiles, nil
+	}
+
+	ordered := []string{}
+	err = cs.customFs.UnmarshalFromFile(filepath.Join(dirPath, config.ConditionsOrderFile), &ordered)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", config.ConditionsOrderFile, err.Error())
+	}
+	listed := map[string]bool{}
+	for _, name := range ordered {
+		if listed[name] {
+			return nil, fmt.Errorf("%s lists %s more than once", config.ConditionsOrderFile, name)
+		}
+		if !cs.customFs.Exists(filepath.Join(dirPath, name)) {
+			return nil, fmt.Errorf("%s lists %s which does not exist", config.ConditionsOrderFile, name)
+		}
+		listed[name] = true
+	}
+	for _, name := range jsonFiles {
+		if !listed[name] {
+			return nil, fmt.Errorf("%s is not listed in %s", name, config.ConditionsOrderFile)
+		}
+	}
+	return ordered, nil
+}
+
+type conditionFile struct {
+	name       string
+	conditions []model.Condition
+}
+
+// readConditionFiles returns the contents of every condition file in dirPath, in order, without duplicate names.
+func (cs *ClientStore) readConditionFiles(dirPath string) ([]conditionFile, error) {
+	names, err := cs.conditionFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	files := []conditionFile{}
+	definedIn := map[string]string{}
+	var errs SourceErrors
+	for _, name := range names {
+		path := filepath.Join(dirPath, name)
+		fileConditions := []model.Condition{}
+		err := cs.customFs.UnmarshalFromFile(path, &fileConditions)
+		if err != nil && err != io.EOF {
+			errs = append(errs, newSourceError(path, "", err))
+			continue
+		}
+		file := conditionFile{name: name, conditions: []model.Condition{}}
+		for _, condition := range fileConditions {
+			if previous, ok := definedIn[condition.Name]; ok {
+				err := fmt.Errorf("duplicate condition %s in %s and %s", condition.Name, previous, name)
+				errs = append(errs, &SourceError{File: path, Key: condition.Name, Err: err})
+				continue
+			}
+			definedIn[condition.Name] = name
+			file.conditions = append(file.conditions, condition)
+		}
+		files = append(files, file)
+	}
+	return files, errs.orNil()
+}
+
+func (cs *ClientStore) readConditions(dirPath string) ([]model.Condition, error) {
+	files, err := cs.readConditionFiles(dirPath)
+	conditions := []model.Condition{}
+	for i := range files {
+		conditions = append(conditions, files[i].conditions...)
+	}
+	return conditions, err
+}
+
+// writeConditions replaces the condition files in dirPath with the given conditions.
+func (cs *ClientStore) writeConditions(conditions []model.Condition, dirPath string, splitBy string) error {
+	files, err := splitConditions(conditions, splitBy)
+	if err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	for _, file := range files {
+		if err := cs.writeJson(file.conditions, filepath.Join(dirPath, file.name)); err != nil {
+			return err
+		}
+		written[file.name] = true
+	}
+	if !cs.customFs.Exists(dirPath) {
+		return nil
+	}
+	existing, err := cs.customFs.ListFiles(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range existing {
+		if filepath.Ext(name) != ".json" || written[name] {
+			continue
+		}
+		if err := cs.customFs.Remove(filepath.Join(dirPath, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitConditions(conditions []model.Condition, splitBy string) ([]conditionFile, error) {
+	switch splitBy {
+	case "", SplitByNone:
+		return []conditionFile{{name: config.ConditionsFile, conditions: conditions}}, nil
+	case SplitByPrefix:
+		runs := splitConditionsByPrefix(conditions)
+		width := len(fmt.Sprint(len(runs)))
+		if width < 3 {
+			width = 3
+		}
+		files := make([]conditionFile, len(runs))
+		for i := range runs {
+			files[i] = conditionFile{name: fmt.Sprintf("%0*d-%s.json", width, i+1, runs[i].prefix), conditions: runs[i].conditions}
+		}
+		return files, nil
+	default:
+		return nil, fmt.Errorf("unknown split mode %s", splitBy)
+	}
+}
+
+type conditionRun struct {
+	prefix     string
+	conditions []model.Condition
+}
+
+func splitConditionsByPrefix(conditions []model.Condition) []conditionRun {
+	runs := []conditionRun{}
+	for _, condition := range conditions {
+		prefix := namePrefix(condition.Name)
+		if len(runs) == 0 || runs[len(runs)-1].prefix != prefix {
+			runs = append(runs, conditionRun{prefix: prefix})
+		}
+		runs[len(runs)-1].conditions = append(runs[len(runs)-1].conditions, condition)
+	}
+	return runs
+}
+
+// namePrefix returns the lower-cased part of name before the first separator, made safe for use in a file name.
+func namePrefix(name string) string {
+	if i := strings.IndexAny(name, "_- ."); i > 0 {
+		name = name[:i]
+	}
+	sb := strings.Builder{}
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "default"
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package firebase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionsTestSuite struct {
+	suite.Suite
+	fs afero.Fs
+	cs *ClientStore
+}
+
+func (c *ConditionsTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	c.cs = &ClientStore{customFs: &customFs{fs: c.fs}}
+}
+
+func (c *ConditionsTestSuite) writeFile(path, contents string) {
+	c.fs.MkdirAll(filepath.Dir(path), 0744)
+	f, err := c.fs.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644)
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(contents)
+}
+
+func (c *ConditionsTestSuite) TestReadsFilesInNameOrder() {
+	c.writeFile("cfg/conditions/2-b.json", `[{"name":"b","expression":"true"}]`)
+	c.writeFile("cfg/conditions/1-a.json", `[{"name":"a","expression":"true"},{"name":"c","expression":"true"}]`)
+	conditions, err := c.cs.readConditions("cfg/conditions")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"a", "c", "b"}, conditionNames(conditions))
+}
+
+func (c *ConditionsTestSuite) TestReadsFilesInManifestOrder() {
+	c.writeFile("cfg/conditions/a.json", `[{"name":"a","expression":"true"}]`)
+	c.writeFile("cfg/conditions/b.json", `[{"name":"b","expression":"true"}]`)
+	c.writeFile("cfg/conditions/order.json", `["b.json","a.json"]`)
+	conditions, err := c.cs.readConditions("cfg/conditions")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"b", "a"}, conditionNames(conditions))
+
+	c.writeFile("cfg/conditions/order.json", `["b.json"]`)
+	_, err = c.cs.readConditions("cfg/conditions")
+	assert.Contains(c.T(), err.Error(), "a.json is not listed in order.json")
+
+	c.writeFile("cfg/conditions/order.json", `["b.json","a.json","c.json"]`)
+	_, err = c.cs.readConditions("cfg/conditions")
+	assert.Contains(c.T(), err.Error(), "c.json which does not exist")
+}
+
+func (c *ConditionsTestSuite) TestDuplicateNamesAcrossFiles() {
+	c.writeFile("cfg/conditions/a.json", `[{"name":"a","expression":"true"}]`)
+	c.writeFile("cfg/conditions/b.json", `[{"name":"a","expression":"false"}]`)
+	_, err := c.cs.readConditions("cfg/conditions")
+	assert.Contains(c.T(), err.Error(), "duplicate condition a in a.json and b.json")
+}
+
+func (c *ConditionsTestSuite) TestWriteSplitByPrefixRoundTrips() {
+	c.writeFile("cfg/conditions/conditions.json", `[]`)
+	conditions := []model.Condition{
+		{Name: "ios_new", Expression: "device.os == 'ios'"},
+		{Name: "ios_old", Expression: "device.os == 'ios'"},
+		{Name: "Android-beta", Expression: "device.os == 'android'"},
+		{Name: "ios_beta", Expression: "device.os == 'ios'"},
+	}
+	err := c.cs.writeConditions(conditions, "cfg/conditions", SplitByPrefix)
+	assert.NoError(c.T(), err)
+	files, _ := c.cs.customFs.ListFiles("cfg/conditions")
+	assert.Equal(c.T(), []string{"001-ios.json", "002-android.json", "003-ios.json"}, files)
+
+	read, err := c.cs.readConditions("cfg/conditions")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), conditions, read)
+}
+
+func (c *ConditionsTestSuite) TestWriteUnknownSplitModeKeepsFiles() {
+	c.writeFile("cfg/conditions/conditions.json", `[{"name":"a","expression":"true"}]`)
+	err := c.cs.writeConditions([]model.Condition{}, "cfg/conditions", "suffix")
+	assert.EqualError(c.T(), err, "unknown split mode suffix")
+	read, err := c.cs.readConditions("cfg/conditions")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"a"}, conditionNames(read))
+}
+
+func conditionNames(conditions []model.Condition) []string {
+	names := []string{}
+	for i := range conditions {
+		names = append(names, conditions[i].Name)
+	}
+	return names
+}
+
+func TestConditions(t *testing.T) {
+	suite.Run(t, new(ConditionsTestSuite))
+}
@@ -0,0 +1,68 @@
+package firebase
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rapido-labs/firebase-ctl/internal/jsonpos"
+)
+
+// SourceError is a problem with one source file.
+type SourceError struct {
+	File string
+	// Line and Column locate the problem in File, starting at 1. They are 0 when the problem is not at a position.
+	Line   int
+	Column int
+	// Key is the parameter key or the condition name the problem is about, if any.
+	Key string
+	Err error
+}
+
+// newSourceError returns a SourceError for err, taking its position when it has one.
+func newSourceError(file, key string, err error) *SourceError {
+	if posErr, ok := err.(*jsonpos.Error); ok {
+		return &SourceError{File: file, Line: posErr.Line, Column: posErr.Column, Key: key, Err: posErr.Err}
+	}
+	return &SourceError{File: file, Key: key, Err: err}
+}
+
+func (e *SourceError) Error() string {
+	if e.Line != 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Err.Error())
+	}
+	return e.File + ": " + e.Err.Error()
+}
+
+// SourceErrors are every problem found while reading the sources, in the order they were found.
+type SourceErrors []*SourceError
+
+func (e SourceErrors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return strings.Join(messages, "\n\t")
+}
+
+// orNil returns e as an error, or nil when it is empty.
+func (e SourceErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// appendSourceErrors adds the problems of err, found while reading path, to errs.
+func appendSourceErrors(errs SourceErrors, path string, err error) SourceErrors {
+	switch err := err.(type) {
+	case nil:
+		return errs
+	case SourceErrors:
+		return append(errs, err...)
+	case *os.PathError:
+		return append(errs, &SourceError{File: path, Err: err.Err})
+	default:
+		return append(errs, newSourceError(path, "", err))
+	}
+}
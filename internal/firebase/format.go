@@ -0,0 +1,298 @@
+package firebase
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// SetFormat sets the serialization used for every file the ClientStore writes.
+func (cs *ClientStore) SetFormat(format config.FormatConfig) {
+	cs.format = format
+}
+
+func (cs *ClientStore) formatConfig() config.FormatConfig {
+	format := cs.format
+	if format.Indent == "" {
+		format.Indent = config.DefaultToolConfig().Format.Indent
+	}
+	return format
+}
+
+// encodeCanonical serializes data with the configured indentation and a trailing newline.
+func (cs *ClientStore) encodeCanonical(data interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", cs.formatConfig().Indent)
+	err := encoder.Encode(data)
+	return buffer.Bytes(), err
+}
+
+func (cs *ClientStore) writeJson(data interface{}, filePath string) error {
+	contents, err := cs.encodeCanonical(data)
+	if err != nil {
+		return err
+	}
+	return cs.customFs.WriteBytesToFile(contents, filePath)
+}
+
+// canonicalParameters returns the parameters of a file in the form they are written in.
+func (cs *ClientStore) canonicalParameters(keys []string, parameters map[string]model.Parameter) orderedParameters {
+	format := cs.formatConfig()
+	if format.SortKeys {
+		keys = nil
+	}
+	if format.PrettyJsonValues {
+		pretty := map[string]model.Parameter{}
+		for key, parameter := range parameters {
+			pretty[key] = prettyJsonParameter(parameter, format.Indent)
+		}
+		parameters = pretty
+	}
+	return orderedParameters{keys: keys, parameters: parameters}
+}
+
+func prettyJsonParameter(parameter model.Parameter, indent string) model.Parameter {
+	if strings.ToLower(parameter.ValueType) != "json" {
+		return parameter
+	}
+	if parameter.DefaultValue != nil {
+		value := *parameter.DefaultValue
+		value.ExplicitValue = prettyJson(value.ExplicitValue, indent)
+		parameter.DefaultValue = &value
+	}
+	if parameter.ConditionalValues != nil {
+		conditionalValues := map[string]model.ParameterValue{}
+		for name, value := range parameter.ConditionalValues {
+			value.ExplicitValue = prettyJson(value.ExplicitValue, indent)
+			conditionalValues[name] = value
+		}
+		parameter.ConditionalValues = conditionalValues
+	}
+	return parameter
+}
+
+// prettyJson indents value when it is compact json.
+func prettyJson(value, indent string) string {
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, []byte(value)); err != nil || compact.String() != value {
+		return value
+	}
+	buffer := &bytes.Buffer{}
+	if err := json.Indent(buffer, []byte(value), "", indent); err != nil {
+		return value
+	}
+	return buffer.String()
+}
+
+func compactJsonParameter(parameter model.Parameter, indent string) model.Parameter {
+	if strings.ToLower(parameter.ValueType) != "json" {
+		return parameter
+	}
+	if parameter.DefaultValue != nil {
+		value := *parameter.DefaultValue
+		value.ExplicitValue = compactJson(value.ExplicitValue, indent)
+		parameter.DefaultValue = &value
+	}
+	if parameter.ConditionalValues != nil {
+		conditionalValues := map[string]model.ParameterValue{}
+		for name, value := range parameter.ConditionalValues {
+			value.ExplicitValue = compactJson(value.ExplicitValue, indent)
+			conditionalValues[name] = value
+		}
+		parameter.ConditionalValues = conditionalValues
+	}
+	return parameter
+}
+
+// compactJson undoes prettyJson.
+func compactJson(value, indent string) string {
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, []byte(value)); err != nil {
+		return value
+	}
+	if prettyJson(compact.String(), indent) != value {
+		return value
+	}
+	return compact.String()
+}
+
+func (cs *ClientStore) readParameters(path string, parameters map[string]model.Parameter) error {
+	err := cs.customFs.UnmarshalFromFile(path, &parameters)
+	format := cs.formatConfig()
+	if !format.PrettyJsonValues {
+		return err
+	}
+	for key, parameter := range parameters {
+		parameters[key] = compactJsonParameter(parameter, format.Indent)
+	}
+	return err
+}
+
+// FormatLocalConfig rewrites the files in dir in canonical form, or only checks them, and returns the unformatted ones.
+func (cs *ClientStore) FormatLocalConfig(dir string, check bool) ([]string, error) {
+	unformatted := []string{}
+	conditionsDirPath := filepath.Join(dir, config.ConditionsDir)
+	conditionFiles, err := cs.readConditionFiles(conditionsDirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range conditionFiles {
+		relPath := filepath.Join(config.ConditionsDir, file.name)
+		changed, err := cs.formatFile(file.conditions, filepath.Join(dir, relPath), check)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			unformatted = append(unformatted, relPath)
+		}
+	}
+
+	parameterFiles, err := cs.readParameterFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, relPath := range sortedKeys(parameterFiles) {
+		path := filepath.Join(dir, relPath)
+		order, _ := cs.customFs.ReadKeyOrder(path)
+		changed, err := cs.formatFile(cs.canonicalParameters(order, parameterFiles[relPath]), path, check)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			unformatted = append(unformatted, relPath)
+		}
+	}
+	return unformatted, nil
+}
+
+// formatFile writes data to path in canonical form, keeping the members data does not know of.
+func (cs *ClientStore) formatFile(data interface{}, path string, check bool) (bool, error) {
+	current, err := cs.customFs.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	known, err := cs.encodeCanonical(data)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := cs.indentCanonical(withUnknownMembers(known, current))
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(current, formatted) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	return true, cs.customFs.WriteBytesToFile(formatted, path)
+}
+
+func sortedKeys(files map[string]map[string]model.Parameter) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (cs *ClientStore) indentCanonical(data []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := json.Indent(buffer, data, "", cs.formatConfig().Indent); err != nil {
+		return nil, err
+	}
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
+
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+// withUnknownMembers appends the object members of original that known lacks to known.
+func withUnknownMembers(known, original json.RawMessage) json.RawMessage {
+	knownMembers, isObject := objectMembers(known)
+	originalMembers, wasObject := objectMembers(original)
+	if isObject && wasObject {
+		originalValues := map[string]json.RawMessage{}
+		for _, m := range originalMembers {
+			originalValues[m.key] = m.value
+		}
+		present := map[string]bool{}
+		for i, m := range knownMembers {
+			present[m.key] = true
+			if value, ok := originalValues[m.key]; ok {
+				knownMembers[i].value = withUnknownMembers(m.value, value)
+			}
+		}
+		for _, m := range originalMembers {
+			if !present[m.key] {
+				knownMembers = append(knownMembers, m)
+				present[m.key] = true
+			}
+		}
+		return encodeMembers(knownMembers)
+	}
+	var knownElements, originalElements []json.RawMessage
+	if json.Unmarshal(known, &knownElements) != nil || json.Unmarshal(original, &originalElements) != nil ||
+		knownElements == nil || len(knownElements) != len(originalElements) {
+		return known
+	}
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('[')
+	for i := range knownElements {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(withUnknownMembers(knownElements[i], originalElements[i]))
+	}
+	buffer.WriteByte(']')
+	return buffer.Bytes()
+}
+
+func objectMembers(data json.RawMessage) ([]member, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if delim, ok := token.(json.Delim); err != nil || !ok || delim != '{' {
+		return nil, false
+	}
+	members := []member{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		members = append(members, member{key: token.(string), value: value})
+	}
+	return members, true
+}
+
+func encodeMembers(members []member) json.RawMessage {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, _ := utils.JSONMarshal(m.key)
+		buffer.Write(bytes.TrimSpace(key))
+		buffer.WriteByte(':')
+		buffer.Write(m.value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes()
+}
@@ -0,0 +1,111 @@
+package firebase
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatTestSuite struct {
+	suite.Suite
+	cs *ClientStore
+}
+
+func (c *FormatTestSuite) SetupTest() {
+	c.cs = &ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}}
+	c.cs.customFs.WriteBytesToFile([]byte(`[{"name":"a","expression":"true","tagColor":"BLUE"}]`), "cfg/conditions/conditions.json")
+	c.cs.customFs.WriteBytesToFile([]byte(`{"b":{"valueType":"json","defaultValue":{"value":"{\"x\":1}"}},"a":{"defaultValue":{"value":"<a>"},"valueType":"string"}}`), "cfg/parameters/parameters.json")
+}
+
+func (c *FormatTestSuite) TestCheckReportsWithoutWriting() {
+	unformatted, err := c.cs.FormatLocalConfig("cfg", true)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"conditions/conditions.json", "parameters/parameters.json"}, unformatted)
+
+	unformatted, err = c.cs.FormatLocalConfig("cfg", true)
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), unformatted, 2)
+}
+
+func (c *FormatTestSuite) TestFormatIsStable() {
+	c.cs.SetFormat(config.FormatConfig{Indent: "  ", PrettyJsonValues: true})
+	_, err := c.cs.FormatLocalConfig("cfg", false)
+	assert.NoError(c.T(), err)
+	unformatted, err := c.cs.FormatLocalConfig("cfg", true)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), unformatted)
+
+	contents, _ := c.cs.customFs.ReadFile("cfg/parameters/parameters.json")
+	assert.Equal(c.T(), `{
+  "b": {
+    "conditionalValues": null,
+    "defaultValue": {
+      "value": "{\n  \"x\": 1\n}"
+    },
+    "description": "",
+    "valueType": "json"
+  },
+  "a": {
+    "conditionalValues": null,
+    "defaultValue": {
+      "value": "<a>"
+    },
+    "description": "",
+    "valueType": "string"
+  }
+}
+`, string(contents))
+}
+
+func (c *FormatTestSuite) TestSortKeys() {
+	c.cs.SetFormat(config.FormatConfig{SortKeys: true})
+	_, err := c.cs.FormatLocalConfig("cfg", false)
+	assert.NoError(c.T(), err)
+	order, _ := c.cs.customFs.ReadKeyOrder("cfg/parameters/parameters.json")
+	assert.Equal(c.T(), []string{"a", "b"}, order)
+}
+
+func (c *FormatTestSuite) TestPrettyJsonValuesArePublishedCompact() {
+	c.cs.SetFormat(config.FormatConfig{Indent: "  ", PrettyJsonValues: true})
+	c.cs.customFs.WriteBytesToFile([]byte(`{"b":{"valueType":"json","defaultValue":{"value":"{\"x\":1}"}},"c":{"valueType":"json","defaultValue":{"value":"{ \"y\": 2 }"}}}`), "cfg/parameters/parameters.json")
+	_, err := c.cs.FormatLocalConfig("cfg", false)
+	assert.NoError(c.T(), err)
+
+	local, err := c.cs.GetLocalConfig("cfg")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), `{"x":1}`, local.Parameters["b"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), `{ "y": 2 }`, local.Parameters["c"].DefaultValue.ExplicitValue)
+}
+
+func (c *FormatTestSuite) TestFormatKeepsUnknownMembers() {
+	c.cs.customFs.WriteBytesToFile([]byte(`{"a":{"valueType":"string","rollout":{"percent":5},"defaultValue":{"value":"1","personalization":true}}}`), "cfg/parameters/parameters.json")
+	_, err := c.cs.FormatLocalConfig("cfg", false)
+	assert.NoError(c.T(), err)
+	contents, _ := c.cs.customFs.ReadFile("cfg/parameters/parameters.json")
+	assert.Equal(c.T(), `{
+	"a": {
+		"conditionalValues": null,
+		"defaultValue": {
+			"value": "1",
+			"personalization": true
+		},
+		"description": "",
+		"valueType": "string",
+		"rollout": {
+			"percent": 5
+		}
+	}
+}
+`, string(contents))
+
+	unformatted, err := c.cs.FormatLocalConfig("cfg", true)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), unformatted)
+}
+
+func TestFormat(t *testing.T) {
+	suite.Run(t, new(FormatTestSuite))
+}
@@ -3,9 +3,13 @@ package firebase
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/rapido-labs/firebase-ctl/internal/jsonpos"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
 	"github.com/spf13/afero"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -43,23 +47,88 @@ func (f *customFs) UnMarshalFromDir(dirName string, data interface{}) error {
 	return nil
 
 }
+// UnmarshalFromFile decodes the json in fileName into data, returning problems as a *jsonpos.Error.
 func (f *customFs) UnmarshalFromFile(fileName string, data interface{}) error {
-	file, err := f.fs.Open(fileName)
+	contents, err := afero.ReadFile(f.fs, fileName)
 	if err != nil {
 		return err
 	}
+	return jsonpos.Decode(contents, data)
+}
+// ListFiles returns the names of the regular files in dirName, sorted lexicographically.
+func (f *customFs) ListFiles(dirName string) ([]string, error) {
+	dir, err := f.fs.Open(dirName)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	fileInfoList, err := dir.Readdir(0)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, fileInfo := range fileInfoList {
+		if fileInfo.IsDir() {
+			continue
+		}
+		names = append(names, fileInfo.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+func (f *customFs) Exists(path string) bool {
+	_, err := f.fs.Stat(path)
+	return err == nil
+}
+func (f *customFs) Remove(path string) error {
+	return f.fs.Remove(path)
+}
+// ReadKeyOrder returns the keys of the json object in fileName in the order they appear in the file.
+func (f *customFs) ReadKeyOrder(fileName string) ([]string, error) {
+	file, err := f.fs.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
-	return json.NewDecoder(file).Decode(data)
+	decoder := json.NewDecoder(file)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("%s does not contain a json object", fileName)
+	}
+	keys := []string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
 }
 func (f *customFs) WriteJsonToFile(data interface{}, filePath string) error {
+	contents, err := utils.JSONMarshal(data)
+	if err != nil {
+		return err
+	}
+	return f.WriteBytesToFile(contents, filePath)
+}
+func (f *customFs) WriteBytesToFile(contents []byte, filePath string) error {
 	f.fs.MkdirAll(filepath.Dir(filePath), 0744)
 	file, err := f.fs.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	d := json.NewEncoder(file)
-	d.SetIndent("", "\t")
-	d.SetEscapeHTML(false)
-	return d.Encode(data)
+	_, err = file.Write(contents)
+	return err
+}
+func (f *customFs) ReadFile(fileName string) ([]byte, error) {
+	return afero.ReadFile(f.fs, fileName)
 }
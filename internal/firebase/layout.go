@@ -0,0 +1,215 @@
+package firebase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// Placement rules for parameters that do not exist in the target directory yet.
+const (
+	LayoutDefault = "default"
+	LayoutPrefix  = "prefix"
+	LayoutGroup   = "group"
+)
+
+// readParameterFiles returns the parameters of every parameter file in dir, keyed by its path relative to dir.
+func (cs *ClientStore) readParameterFiles(dir string) (map[string]map[string]model.Parameter, error) {
+	files := map[string]map[string]model.Parameter{}
+	var errs SourceErrors
+	for _, parametersDir := range []string{config.ParametersDir, config.SecretParametersDir} {
+		definedIn := map[string]string{}
+		if !cs.customFs.Exists(filepath.Join(dir, parametersDir)) {
+			continue
+		}
+		names, err := cs.customFs.ListFiles(filepath.Join(dir, parametersDir))
+		if err != nil {
+			errs = append(errs, &SourceError{File: filepath.Join(dir, parametersDir), Err: err})
+			continue
+		}
+		for _, name := range names {
+			relPath := filepath.Join(parametersDir, name)
+			parameters := map[string]model.Parameter{}
+			err := cs.readParameters(filepath.Join(dir, relPath), parameters)
+			if err != nil && err != io.EOF {
+				errs = append(errs, newSourceError(filepath.Join(dir, relPath), "", err))
+				continue
+			}
+			keys := make([]string, 0, len(parameters))
+			for key := range parameters {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if previous, ok := definedIn[key]; ok {
+					err := fmt.Errorf("duplicate parameter %s in %s and %s", key, previous, relPath)
+					errs = append(errs, &SourceError{File: filepath.Join(dir, relPath), Key: key, Err: err})
+					delete(parameters, key)
+					continue
+				}
+				definedIn[key] = relPath
+			}
+			files[relPath] = parameters
+		}
+	}
+	return files, errs.orNil()
+}
+
+// parameterSources returns the file, relative to dir, every parameter of files takes effect from.
+func parameterSources(files map[string]map[string]model.Parameter) map[string]string {
+	sources := map[string]string{}
+	for _, relPath := range sortedKeys(files) {
+		secret := filepath.Dir(relPath) == config.SecretParametersDir
+		for key := range files[relPath] {
+			if previous, ok := sources[key]; ok && !secret && filepath.Dir(previous) == config.SecretParametersDir {
+				continue
+			}
+			sources[key] = relPath
+		}
+	}
+	return sources
+}
+
+// layoutParameters keeps existing parameters in their files and places new ones according to opts.
+func (cs *ClientStore) layoutParameters(parameters map[string]model.Parameter, rc *remoteconfig.RemoteConfig, outputDir string, opts BackupOptions) (map[string]map[string]model.Parameter, error) {
+	existing, err := cs.readParameterFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]map[string]model.Parameter{}
+	location := parameterSources(existing)
+	for file, fileParameters := range existing {
+		files[file] = map[string]model.Parameter{}
+		for key, parameter := range fileParameters {
+			if _, ok := parameters[key]; ok && location[key] != file {
+				files[file][key] = parameter
+			}
+		}
+	}
+	groups := parameterGroupsByKey(rc)
+	for key, parameter := range parameters {
+		file, ok := location[key]
+		if ok && existing[file][key].Protected {
+			parameter.Protected = true
+		}
+		if !ok {
+			file, err = placeParameter(key, groups[key], opts)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if files[file] == nil {
+			files[file] = map[string]model.Parameter{}
+		}
+		files[file][key] = parameter
+	}
+	return files, nil
+}
+
+func placeParameter(key, group string, opts BackupOptions) (string, error) {
+	dir := config.ParametersDir
+	if utils.IsSecretKey(key) {
+		dir = config.SecretParametersDir
+	}
+	defaultFile := opts.DefaultParametersFile
+	if defaultFile == "" {
+		defaultFile = config.ParametersFile
+	}
+	switch opts.ParameterLayout {
+	case "", LayoutDefault:
+		return filepath.Join(dir, defaultFile), nil
+	case LayoutPrefix:
+		return filepath.Join(dir, namePrefix(key)+".json"), nil
+	case LayoutGroup:
+		if group == "" {
+			return filepath.Join(dir, defaultFile), nil
+		}
+		return filepath.Join(dir, namePrefix(group)+".json"), nil
+	default:
+		return "", fmt.Errorf("unknown parameter layout %s", opts.ParameterLayout)
+	}
+}
+
+func parameterGroupsByKey(rc *remoteconfig.RemoteConfig) map[string]string {
+	groups := map[string]string{}
+	for name, group := range rc.ParameterGroups {
+		for key := range group.Parameters {
+			groups[key] = name
+		}
+	}
+	return groups
+}
+
+// writeParameterFiles writes every non-empty file below outputDir and removes the ones left empty.
+func (cs *ClientStore) writeParameterFiles(files map[string]map[string]model.Parameter, outputDir string) error {
+	for _, name := range sortedKeys(files) {
+		path := filepath.Join(outputDir, name)
+		if len(files[name]) == 0 {
+			if err := cs.customFs.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		order, _ := cs.customFs.ReadKeyOrder(path)
+		err := cs.writeJson(cs.canonicalParameters(order, files[name]), path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderedParameters marshals parameters in the order of keys, followed by the remaining parameters sorted by key.
+type orderedParameters struct {
+	keys       []string
+	parameters map[string]model.Parameter
+}
+
+func (o orderedParameters) orderedKeys() []string {
+	ordered := []string{}
+	seen := map[string]bool{}
+	for _, key := range o.keys {
+		if _, ok := o.parameters[key]; ok && !seen[key] {
+			ordered = append(ordered, key)
+			seen[key] = true
+		}
+	}
+	remaining := []string{}
+	for key := range o.parameters {
+		if !seen[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	return append(ordered, remaining...)
+}
+
+func (o orderedParameters) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range o.orderedKeys() {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, err := utils.JSONMarshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := utils.JSONMarshal(o.parameters[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(encodedValue)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package firebase
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LayoutTestSuite struct {
+	suite.Suite
+	fs afero.Fs
+	cs *ClientStore
+}
+
+func (c *LayoutTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	c.cs = &ClientStore{customFs: &customFs{fs: c.fs}}
+}
+
+func (c *LayoutTestSuite) writeFile(path, contents string) {
+	c.fs.MkdirAll(filepath.Dir(path), 0744)
+	f, err := c.fs.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644)
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(contents)
+}
+
+func (c *LayoutTestSuite) readFile(path string) string {
+	f, err := c.fs.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	contents, _ := ioutil.ReadAll(f)
+	return string(contents)
+}
+
+func remoteParameter(value string) remoteconfig.Parameter {
+	return remoteconfig.Parameter{DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: value}}
+}
+
+func (c *LayoutTestSuite) TestBackupPreservesExistingLayout() {
+	c.writeFile("out/parameters/payments.json", `{"payments_limit":{"defaultValue":{"value":"1"},"valueType":"string"}}`)
+	c.writeFile("out/parameters/growth.json", `{"growth_banner":{"defaultValue":{"value":"a"},"valueType":"string"}}`)
+	rc := &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"payments_limit": remoteParameter("2"),
+			"search_radius":  remoteParameter("5"),
+			"SEC_api_key":    remoteParameter("secret"),
+		},
+	}
+	err := c.cs.BackupRemoteConfig(rc, "out", BackupOptions{ParameterLayout: LayoutPrefix})
+	assert.NoError(c.T(), err)
+
+	assert.Contains(c.T(), c.readFile("out/parameters/payments.json"), `"value": "2"`)
+	assert.Contains(c.T(), c.readFile("out/parameters/search.json"), "search_radius")
+	assert.Contains(c.T(), c.readFile("out/secret-parameters/sec.json"), "SEC_api_key")
+	assert.False(c.T(), c.cs.customFs.Exists("out/parameters/growth.json"), "file of deleted parameters should be removed")
+
+	localConfig, err := c.cs.GetLocalConfig("out")
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), localConfig.Parameters, 3)
+}
+
+func (c *LayoutTestSuite) TestBackupPlacesByGroup() {
+	rc := &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{"ungrouped": remoteParameter("1")},
+		ParameterGroups: map[string]remoteconfig.ParameterGroup{
+			"Payments": {Parameters: map[string]*remoteconfig.Parameter{"limit": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "3"}}}},
+		},
+	}
+	err := c.cs.BackupRemoteConfig(rc, "out", BackupOptions{ParameterLayout: LayoutGroup, DefaultParametersFile: "misc.json"})
+	assert.NoError(c.T(), err)
+	assert.Contains(c.T(), c.readFile("out/parameters/payments.json"), "limit")
+	assert.Contains(c.T(), c.readFile("out/parameters/misc.json"), "ungrouped")
+}
+
+func (c *LayoutTestSuite) TestBackupKeepsGroupedParametersInEveryLayout() {
+	rc := &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{"ungrouped": remoteParameter("1")},
+		ParameterGroups: map[string]remoteconfig.ParameterGroup{
+			"Payments": {Parameters: map[string]*remoteconfig.Parameter{"limit": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "3"}}}},
+		},
+	}
+	err := c.cs.BackupRemoteConfig(rc, "out", BackupOptions{})
+	assert.NoError(c.T(), err)
+	parameters := c.readFile("out/parameters/parameters.json")
+	assert.Contains(c.T(), parameters, "ungrouped")
+	assert.Contains(c.T(), parameters, "limit", "grouped parameters should be backed up with the default layout")
+
+	err = c.cs.BackupRemoteConfig(rc, "prefixed", BackupOptions{ParameterLayout: LayoutPrefix})
+	assert.NoError(c.T(), err)
+	assert.Contains(c.T(), c.readFile("prefixed/parameters/limit.json"), "limit")
+}
+
+func (c *LayoutTestSuite) TestDuplicateParametersAreRejected() {
+	c.writeFile("out/parameters/a.json", `{"key":{"defaultValue":{"value":"1"}}}`)
+	c.writeFile("out/parameters/b.json", `{"key":{"defaultValue":{"value":"1"}}}`)
+	_, err := c.cs.readParameterFiles("out")
+	assert.Contains(c.T(), err.Error(), "duplicate parameter key in parameters/a.json and parameters/b.json")
+}
+
+func (c *LayoutTestSuite) TestSecretParametersOverrideParameters() {
+	c.writeFile("out/parameters/a.json", `{"key":{"defaultValue":{"value":"placeholder"}}}`)
+	c.writeFile("out/secret-parameters/z.json", `{"key":{"defaultValue":{"value":"secret"}}}`)
+	c.writeFile("out/conditions/conditions.json", `[]`)
+	localConfig, err := c.cs.GetLocalConfig("out")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "secret", localConfig.Parameters["key"].DefaultValue.ExplicitValue)
+	parameters, _, err := c.cs.SourceFiles("out")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), map[string]string{"key": "secret-parameters/z.json"}, parameters)
+
+	rc := &remoteconfig.RemoteConfig{Parameters: map[string]remoteconfig.Parameter{
+		"key": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "rotated"}},
+	}}
+	assert.NoError(c.T(), c.cs.BackupRemoteConfig(rc, "out", BackupOptions{}))
+	assert.Contains(c.T(), c.readFile("out/parameters/a.json"), "placeholder")
+	assert.Contains(c.T(), c.readFile("out/secret-parameters/z.json"), "rotated")
+}
+
+func (c *LayoutTestSuite) TestSourceFiles() {
+	c.writeFile("/src/parameters/a.json", `{"a": {}}`)
+	c.writeFile("/src/secret-parameters/b.json", `{"SEC_b": {}}`)
+	c.writeFile("/src/conditions/001-ios.json", `[{"name": "ios", "expression": "true"}]`)
+	parameters, conditions, err := c.cs.SourceFiles("/src")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), map[string]string{"a": "parameters/a.json", "SEC_b": "secret-parameters/b.json"}, parameters)
+	assert.Equal(c.T(), map[string]string{"ios": "conditions/001-ios.json"}, conditions)
+}
+
+func (c *LayoutTestSuite) TestBackupWithInvalidOptionsWritesNothing() {
+	conditions := `[{"name":"a","expression":"true"}]`
+	c.writeFile("out/conditions/conditions.json", conditions)
+	rc := &remoteconfig.RemoteConfig{Parameters: map[string]remoteconfig.Parameter{"search_radius": remoteParameter("5")}}
+	err := c.cs.BackupRemoteConfig(rc, "out", BackupOptions{ParameterLayout: "feature"})
+	assert.EqualError(c.T(), err, "unknown parameter layout feature")
+	err = c.cs.BackupRemoteConfig(rc, "out", BackupOptions{SplitConditionsBy: "suffix"})
+	assert.EqualError(c.T(), err, "unknown split mode suffix")
+	assert.Equal(c.T(), conditions, c.readFile("out/conditions/conditions.json"))
+	assert.False(c.T(), c.cs.customFs.Exists("out/parameters"))
+}
+
+func TestLayout(t *testing.T) {
+	suite.Run(t, new(LayoutTestSuite))
+}
@@ -0,0 +1,54 @@
+package firebase
+
+import (
+	"strconv"
+
+	"github.com/rapido-labs/firebase-ctl/internal/jsonpos"
+)
+
+// Locate returns the line and column of the member at valuePath under key in the file path, or of its byte at offset.
+func (cs *ClientStore) Locate(path, key string, valuePath []string, offset int) (line, column int, ok bool) {
+	contents, err := cs.customFs.ReadFile(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	doc := jsonpos.Parse(contents)
+	member, isCondition, ok := memberPath(doc, key)
+	if !ok {
+		return 0, 0, false
+	}
+	if len(valuePath) == 0 && isCondition {
+		valuePath = []string{"name"}
+	}
+	node, ok := doc.Lookup(append(member, valuePath...)...)
+	if !ok {
+		node, _ = doc.Lookup(member...)
+		offset = -1
+	}
+	position := node.Value.Start
+	if node.Key.End != 0 {
+		position = node.Key.Start
+	}
+	if _, isString := doc.String(node.Value); isString && offset >= 0 {
+		position = doc.StringOffset(node.Value, offset)
+	}
+	line, column = doc.Location(position)
+	return line, column, true
+}
+
+func memberPath(doc *jsonpos.Document, key string) (path []string, isCondition bool, ok bool) {
+	if _, ok := doc.Lookup(key); ok {
+		return []string{key}, false, true
+	}
+	for i := 0; ; i++ {
+		index := strconv.Itoa(i)
+		if _, ok := doc.Lookup(index); !ok {
+			return nil, false, false
+		}
+		if name, ok := doc.Lookup(index, "name"); ok {
+			if value, _ := doc.String(name.Value); value == key {
+				return []string{index}, true, true
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package firebase
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func (c *LayoutTestSuite) TestLocate() {
+	c.writeFile("src/parameters/a.json", `{
+  "banner": {
+    "defaultValue": {"value": "{\"a\": ]}"},
+    "valueType": "json"
+  }
+}`)
+	c.writeFile("src/conditions/conditions.json", `[
+  {"name": "ios", "expression": "device.os == 'ios'"},
+  {"name": "web", "expression": "device.os =="}
+]`)
+	line, column, ok := c.cs.Locate("src/parameters/a.json", "banner", nil, -1)
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), []int{2, 3}, []int{line, column})
+	line, column, _ = c.cs.Locate("src/parameters/a.json", "banner", []string{"defaultValue", "value"}, 6)
+	assert.Equal(c.T(), []int{3, 40}, []int{line, column})
+	line, column, _ = c.cs.Locate("src/parameters/a.json", "banner", []string{"conditionalValues", "ios"}, -1)
+	assert.Equal(c.T(), []int{2, 3}, []int{line, column})
+
+	line, column, _ = c.cs.Locate("src/conditions/conditions.json", "web", nil, -1)
+	assert.Equal(c.T(), []int{3, 4}, []int{line, column})
+	line, column, _ = c.cs.Locate("src/conditions/conditions.json", "web", []string{"expression"}, -1)
+	assert.Equal(c.T(), []int{3, 19}, []int{line, column})
+
+	_, _, ok = c.cs.Locate("src/parameters/a.json", "missing", nil, -1)
+	assert.False(c.T(), ok)
+}
+
+func (c *LayoutTestSuite) TestLoadErrorsHavePositions() {
+	c.writeFile("src/conditions/conditions.json", `[]`)
+	c.writeFile("src/parameters/a.json", "{\n  \"a\": {},\n  \"a\": {}\n}")
+	c.writeFile("src/parameters/b.json", "{\n  \"b\": \n}")
+	_, err := c.cs.GetLocalConfig("src")
+	errs, ok := err.(SourceErrors)
+	assert.True(c.T(), ok)
+	assert.Len(c.T(), errs, 2)
+	assert.EqualError(c.T(), errs[0], "src/parameters/a.json:3:3: duplicate key a")
+	assert.Equal(c.T(), []int{3, 1}, []int{errs[1].Line, errs[1].Column})
+}
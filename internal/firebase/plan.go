@@ -0,0 +1,153 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/errorutils"
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// Plan is a template ready to be published, along with the changes it makes to the live template.
+type Plan struct {
+	Template remoteconfig.RemoteConfig
+	Changes  utils.ChangeSet
+	// Description is recorded as the description of the published template version.
+	Description string
+	// BaseVersion is the version of the live template the plan was computed against.
+	BaseVersion int64
+	// ETag is the ETag of that version. The plan is only published while it is still live, a plan without one
+	// replaces whatever is live.
+	ETag string
+	// Protected are the keys of the parameters that cannot be deleted, as marked in the sources or on remote.
+	Protected []string
+}
+
+// Selection restricts an apply to some parameters.
+type Selection struct {
+	// Keys are the parameters applied, all the parameters in the owned namespaces when empty.
+	Keys []string
+	// AllowDeletes permits selecting keys that are only defined on remote, which deletes them.
+	AllowDeletes bool
+}
+
+// PlanApply computes the template that applying the parameters of sourceConfig chosen by selection results in.
+func (cs *ClientStore) PlanApply(sourceConfig model.Config, selection Selection) (*Plan, error) {
+	response, err := cs.getLatestRemoteResponse()
+	if err != nil {
+		return nil, err
+	}
+	remoteConfig := response.RemoteConfig
+	var template *remoteconfig.RemoteConfig
+	switch {
+	case len(selection.Keys) != 0:
+		template, err = cs.mergeSelected(sourceConfig, *remoteConfig, selection)
+	case len(cs.namespaces) != 0:
+		template, err = cs.mergeOwned(sourceConfig, *remoteConfig)
+	default:
+		template = sourceConfig.ToRemoteConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{
+		Template:    *template,
+		Changes:     utils.ComputeChanges(*template, *remoteConfig),
+		BaseVersion: remoteConfig.Version.VersionNumber,
+		ETag:        response.Etag,
+		Protected:   protectedParameters(sourceConfig, *remoteConfig),
+	}, nil
+}
+
+// StalePlanError is returned by PublishPlan when the live template changed since the plan was computed.
+type StalePlanError struct {
+	BaseVersion int64
+}
+
+func (e *StalePlanError) Error() string {
+	return fmt.Sprintf("the live template changed since the plan was computed against version %d, plan again", e.BaseVersion)
+}
+
+// UnknownOutcomeError is returned by PublishPlan when the template may or may not have been published.
+type UnknownOutcomeError struct {
+	Err error
+}
+
+func (e *UnknownOutcomeError) Error() string {
+	return fmt.Sprintf("%s, the template may still have been published, check the version history", e.Err.Error())
+}
+
+func (e *UnknownOutcomeError) Unwrap() error {
+	return e.Err
+}
+
+// PublishPlan publishes the template of plan unless the live template changed since, and returns its version.
+func (cs *ClientStore) PublishPlan(plan *Plan) (*remoteconfig.Version, error) {
+	published, err := cs.pushConfigToRemote(plan.Template, false, plan.Description, plan.ETag)
+	if err == nil {
+		return &published.Version, nil
+	}
+	switch {
+	case statusCode(rootCause(err)) == http.StatusPreconditionFailed:
+		return nil, &StalePlanError{BaseVersion: plan.BaseVersion}
+	case outcomeUnknown(err):
+		return nil, &UnknownOutcomeError{Err: err}
+	}
+	return nil, err
+}
+
+// outcomeUnknown tells whether a publish that failed with err may have taken effect.
+func outcomeUnknown(err error) bool {
+	cause := rootCause(err)
+	if status := statusCode(cause); status != 0 {
+		return status >= http.StatusInternalServerError
+	}
+	var timeoutErr *timeoutError
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &timeoutErr) || errors.As(err, &urlErr) || errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) ||
+		errorutils.IsUnavailable(cause) || errorutils.IsInternal(cause) || errorutils.IsDeadlineExceeded(cause)
+}
+
+func rootCause(err error) error {
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+	return err
+}
+
+// protectedParameters returns the keys of the parameters protected in sourceConfig or on remote.
+func protectedParameters(sourceConfig model.Config, remoteConfig remoteconfig.RemoteConfig) []string {
+	protected := map[string]bool{}
+	for key, parameter := range remoteConfig.Parameters {
+		if model.IsProtected(parameter) {
+			protected[key] = true
+		}
+	}
+	for _, group := range remoteConfig.ParameterGroups {
+		for key, parameter := range group.Parameters {
+			if parameter != nil && model.IsProtected(*parameter) {
+				protected[key] = true
+			}
+		}
+	}
+	for key, parameter := range sourceConfig.Parameters {
+		if parameter.Protected {
+			protected[key] = true
+		}
+	}
+	keys := make([]string, 0, len(protected))
+	for key := range protected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
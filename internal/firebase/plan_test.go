@@ -0,0 +1,156 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/emulator"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PlanTestSuite struct {
+	suite.Suite
+	mock *ClientMock
+	cs   *ClientStore
+}
+
+func (c *PlanTestSuite) SetupTest() {
+	c.mock = new(ClientMock)
+	c.cs = &ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}, remoteConfigClient: c.mock}
+}
+
+func (c *PlanTestSuite) TestPlanApplyComputesChanges() {
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"kept":    {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "1"}},
+			"removed": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "1"}},
+		},
+	}}, nil)
+	plan, err := c.cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{
+		"kept": {DefaultValue: &model.ParameterValue{ExplicitValue: "1"}},
+	}}, Selection{})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []utils.ParameterChange{{
+		Key:    "removed",
+		Type:   utils.ChangeDeleted,
+		Before: &remoteconfig.Parameter{DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "1"}},
+	}}, plan.Changes.Parameters)
+}
+
+func (c *PlanTestSuite) TestSelectingARemoteOnlyKeyNeedsDeletes() {
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"removed": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "1"}},
+		},
+	}}, nil)
+	sourceConfig := model.Config{Parameters: map[string]model.Parameter{}}
+	_, err := c.cs.PlanApply(sourceConfig, Selection{Keys: []string{"removed"}})
+	assert.EqualError(c.T(), err, "parameter removed is only defined on remote, applying it deletes it, which needs deletes to be allowed")
+
+	plan, err := c.cs.PlanApply(sourceConfig, Selection{Keys: []string{"removed"}, AllowDeletes: true})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), utils.ChangeDeleted, plan.Changes.Parameters[0].Type)
+}
+
+func (c *PlanTestSuite) TestProtectionOutlivesTheDefinition() {
+	protectedConfig := model.Config{Parameters: map[string]model.Parameter{
+		"important": {DefaultValue: &model.ParameterValue{}, Description: "do not remove", Protected: true},
+		"other":     {DefaultValue: &model.ParameterValue{}},
+	}}
+	published := protectedConfig.ToRemoteConfig()
+	assert.Equal(c.T(), "do not remove [firebase-ctl:protected]", published.Parameters["important"].Description)
+	assert.Equal(c.T(), protectedConfig.Parameters["important"], model.ConvertToSourceParameter(published.Parameters["important"]))
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: published}, nil)
+
+	plan, err := c.cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{}}, Selection{})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"important"}, plan.Protected, "removing the definition should not lift the protection")
+
+	plan, err = c.cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{
+		"important": {DefaultValue: &model.ParameterValue{}, Description: "do not remove"},
+	}}, Selection{})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "do not remove", plan.Template.Parameters["important"].Description, "applying without protected lifts it")
+}
+
+func (c *PlanTestSuite) TestOnlyTheMarkerProtects() {
+	written := remoteconfig.Parameter{DefaultValue: &remoteconfig.ParameterValue{}, Description: "legacy flag [protected]"}
+	assert.False(c.T(), model.IsProtected(written))
+	assert.Equal(c.T(), model.Parameter{DefaultValue: &model.ParameterValue{}, Description: "legacy flag [protected]"}, model.ConvertToSourceParameter(written))
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{"legacy": written},
+	}}, nil)
+
+	plan, err := c.cs.PlanApply(model.Config{Parameters: map[string]model.Parameter{}}, Selection{})
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), plan.Protected, "a description written by a user should not protect the parameter")
+}
+
+func (c *PlanTestSuite) TestPublishPlanSendsTheETag() {
+	c.mock.On("GetRemoteConfig", "").Return(&remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{
+		Version: remoteconfig.Version{VersionNumber: 3},
+	}, Etag: "etag-local-3"}, nil)
+	c.mock.On("PublishTemplate", mock.Anything, mock.MatchedBy(func(template remoteconfig.Template) bool {
+		return template.ETag == "etag-local-3"
+	}), false).Return(&remoteconfig.Template{}, nil).Times(1)
+	plan, err := c.cs.PlanApply(model.Config{}, Selection{})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "etag-local-3", plan.ETag)
+	_, err = c.cs.PublishPlan(plan)
+	assert.NoError(c.T(), err)
+	c.mock.AssertExpectations(c.T())
+}
+
+func (c *PlanTestSuite) TestStalePlanIsRejected() {
+	server := httptest.NewServer(emulator.NewServer(afero.NewMemMapFs(), "/data"))
+	defer server.Close()
+	cs := &ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}, remoteConfigClient: newRestClient(server.URL, DefaultEmulatorProject)}
+	sourceConfig := model.Config{Parameters: map[string]model.Parameter{"key": {DefaultValue: &model.ParameterValue{ExplicitValue: "1"}}}}
+	plan, err := cs.PlanApply(sourceConfig, Selection{})
+	assert.NoError(c.T(), err)
+	concurrent, err := cs.PlanApply(sourceConfig, Selection{})
+	assert.NoError(c.T(), err)
+	_, err = cs.PublishPlan(concurrent)
+	assert.NoError(c.T(), err)
+
+	_, err = cs.PublishPlan(plan)
+	assert.Equal(c.T(), &StalePlanError{BaseVersion: 0}, err)
+}
+
+func (c *PlanTestSuite) TestPublishOutcome() {
+	for _, test := range []struct {
+		err     error
+		unknown bool
+	}{
+		{&timeoutError{timeout: time.Second}, true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, true},
+		{&url.Error{Op: "Put", URL: "http://localhost", Err: io.EOF}, true},
+		{&APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&APIError{StatusCode: http.StatusBadRequest}, false},
+		{&QuotaError{Attempts: 5, Err: &APIError{StatusCode: http.StatusTooManyRequests}}, false},
+	} {
+		c.SetupTest()
+		c.mock.On("PublishTemplate", mock.Anything, mock.Anything, false).Return(nil, test.err)
+		_, err := c.cs.PublishPlan(&Plan{})
+		_, unknown := err.(*UnknownOutcomeError)
+		assert.Equal(c.T(), test.unknown, unknown, test.err.Error())
+		assert.True(c.T(), errors.Is(err, test.err) || !unknown, test.err.Error())
+	}
+}
+
+func TestPlan(t *testing.T) {
+	suite.Run(t, new(PlanTestSuite))
+}
@@ -0,0 +1,106 @@
+package firebase
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// PullRemoteConfig merges rc into the sources in dir in place and returns the entries changed on both sides.
+func (cs *ClientStore) PullRemoteConfig(rc *remoteconfig.RemoteConfig, dir string, opts BackupOptions) (*utils.MergeResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	localConfig, err := cs.GetLocalConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading local config: %s", err.Error())
+	}
+	base, err := cs.readLastPull(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading last pull snapshot: %s", err.Error())
+	}
+	remoteConfig := model.ConvertToSourceConfig(*rc)
+	result := utils.MergeRemote(base, *localConfig, *remoteConfig)
+	for key, parameter := range result.Parameters {
+		if parameter.ValueType == "" {
+			parameter.ValueType = inferValueType(parameter)
+			result.Parameters[key] = parameter
+		}
+	}
+	for key, parameter := range result.Base.Parameters {
+		parameter.ValueType = inferValueType(parameter)
+		result.Base.Parameters[key] = parameter
+	}
+
+	parameterFiles, err := cs.layoutParameters(result.Parameters, rc, dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error reading existing parameter files: %s", err.Error())
+	}
+	err = cs.writeMergedConditions(result.Conditions, filepath.Join(dir, config.ConditionsDir))
+	if err != nil {
+		return nil, fmt.Errorf("error writing to conditions file: %s", err.Error())
+	}
+	err = cs.writeParameterFiles(parameterFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error writing to parameter file: %s", err.Error())
+	}
+	err = cs.writeLastPull(result.Base, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error writing last pull snapshot: %s", err.Error())
+	}
+	return &result, nil
+}
+
+// writeMergedConditions writes conditions into the existing condition files of dirPath, keeping each in its file.
+func (cs *ClientStore) writeMergedConditions(conditions []model.Condition, dirPath string) error {
+	files, err := cs.readConditionFiles(dirPath)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		files = []conditionFile{{name: config.ConditionsFile}}
+	}
+	fileOf := map[string]int{}
+	for i := range files {
+		for _, condition := range files[i].conditions {
+			fileOf[condition.Name] = i
+		}
+		files[i].conditions = []model.Condition{}
+	}
+	current := 0
+	for _, condition := range conditions {
+		if i, ok := fileOf[condition.Name]; ok {
+			current = i
+		}
+		files[current].conditions = append(files[current].conditions, condition)
+	}
+	for i := range files {
+		err := cs.writeJson(files[i].conditions, filepath.Join(dirPath, files[i].name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLastPull returns the remote config recorded by the last pull into dir, or nil when there is none.
+func (cs *ClientStore) readLastPull(dir string) (*model.Config, error) {
+	path := filepath.Join(dir, config.StateDir, config.LastPullFile)
+	if !cs.customFs.Exists(path) {
+		return nil, nil
+	}
+	base := &model.Config{}
+	err := cs.customFs.UnmarshalFromFile(path, base)
+	if err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+func (cs *ClientStore) writeLastPull(base model.Config, dir string) error {
+	return cs.writeJson(base, filepath.Join(dir, config.StateDir, config.LastPullFile))
+}
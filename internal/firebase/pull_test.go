@@ -0,0 +1,78 @@
+package firebase
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PullTestSuite struct {
+	suite.Suite
+	cs *ClientStore
+}
+
+func (c *PullTestSuite) SetupTest() {
+	c.cs = &ClientStore{customFs: &customFs{fs: afero.NewMemMapFs()}}
+}
+
+func (c *PullTestSuite) TestPullPreservesLayoutAndReportsConflicts() {
+	initial := &remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"zeta":  remoteParameter("1"),
+			"alpha": remoteParameter("1"),
+		},
+	}
+	err := c.cs.BackupRemoteConfig(initial, "cfg", BackupOptions{})
+	assert.NoError(c.T(), err)
+	assert.False(c.T(), c.cs.customFs.Exists("cfg/.firebase-ctl/last-pull.json"))
+	_, err = c.cs.PullRemoteConfig(initial, "cfg", BackupOptions{})
+	assert.NoError(c.T(), err)
+
+	// reorder the keys, change the value type of zeta and edit alpha locally
+	err = c.cs.customFs.WriteJsonToFile(orderedParameters{
+		keys: []string{"zeta", "alpha"},
+		parameters: map[string]model.Parameter{
+			"zeta":  {DefaultValue: &model.ParameterValue{ExplicitValue: "1"}, ValueType: "json"},
+			"alpha": {DefaultValue: &model.ParameterValue{ExplicitValue: "local"}, ValueType: "string"},
+		},
+	}, "cfg/parameters/parameters.json")
+	assert.NoError(c.T(), err)
+
+	remote := &remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"zeta":  remoteParameter("2"),
+			"alpha": remoteParameter("remote"),
+			"beta":  remoteParameter("1"),
+		},
+	}
+	result, err := c.cs.PullRemoteConfig(remote, "cfg", BackupOptions{})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"beta", "zeta"}, result.Updated)
+	assert.Len(c.T(), result.Conflicts, 1)
+	assert.Equal(c.T(), "alpha", result.Conflicts[0].Key)
+
+	pulled, err := c.cs.GetLocalConfig("cfg")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "2", pulled.Parameters["zeta"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "json", pulled.Parameters["zeta"].ValueType)
+	assert.Equal(c.T(), "local", pulled.Parameters["alpha"].DefaultValue.ExplicitValue)
+
+	order, err := c.cs.customFs.ReadKeyOrder("cfg/parameters/parameters.json")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []string{"zeta", "alpha", "beta"}, order)
+
+	// the conflict is reported again until it is resolved
+	result, err = c.cs.PullRemoteConfig(remote, "cfg", BackupOptions{})
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), result.Conflicts, 1)
+}
+
+func TestPull(t *testing.T) {
+	suite.Run(t, new(PullTestSuite))
+}
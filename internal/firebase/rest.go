@@ -0,0 +1,166 @@
+package firebase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+)
+
+// restClient is a ConfigClient talking to a Remote Config REST endpoint, such as the emulator, without authentication.
+type restClient struct {
+	endpoint   string
+	projectID  string
+	httpClient *http.Client
+}
+
+// APIError is an error response of a Remote Config REST endpoint.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+	// RetryAfter is the delay the endpoint asked for before the request is retried, 0 when it did not.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+func newRestClient(endpoint, projectID string) *restClient {
+	return &restClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		projectID:  projectID,
+		httpClient: &http.Client{},
+	}
+}
+
+func (c *restClient) rootURL() string {
+	return fmt.Sprintf("%s/v1/projects/%s/remoteConfig", c.endpoint, c.projectID)
+}
+
+func (c *restClient) GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error) {
+	query := url.Values{}
+	if versionNumber != "" {
+		query.Set("versionNumber", versionNumber)
+	}
+	data := &remoteconfig.RemoteConfig{}
+	header, err := c.do(ctx, http.MethodGet, c.rootURL(), query, nil, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteconfig.Response{RemoteConfig: data, Etag: header.Get("Etag")}, nil
+}
+
+func (c *restClient) PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error) {
+	query := url.Values{"validateOnly": []string{strconv.FormatBool(validateOnly)}}
+	etag := template.ETag
+	if etag == "" {
+		etag = "*"
+	}
+	body := &remoteconfig.RemoteConfig{
+		Conditions:      template.Conditions,
+		Parameters:      template.Parameters,
+		Version:         template.Version,
+		ParameterGroups: template.ParameterGroups,
+	}
+	data := &remoteconfig.RemoteConfig{}
+	header, err := c.do(ctx, http.MethodPut, c.rootURL(), query, map[string]string{"If-Match": etag}, body, data)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteconfig.Template{
+		Conditions:      data.Conditions,
+		Parameters:      data.Parameters,
+		ParameterGroups: data.ParameterGroups,
+		Version:         data.Version,
+		ETag:            header.Get("Etag"),
+	}, nil
+}
+
+func (c *restClient) ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error) {
+	query := url.Values{}
+	if options.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(options.PageSize))
+	}
+	if options.PageToken != "" {
+		query.Set("pageToken", options.PageToken)
+	}
+	if options.EndVersionNumber != "" {
+		query.Set("endVersionNumber", options.EndVersionNumber)
+	}
+	if !options.StartTime.IsZero() {
+		query.Set("startTime", options.StartTime.Format(time.RFC3339Nano))
+	}
+	if !options.EndTime.IsZero() {
+		query.Set("endTime", options.EndTime.Format(time.RFC3339Nano))
+	}
+	data := &remoteconfig.ListVersionsResponse{}
+	_, err := c.do(ctx, http.MethodGet, c.rootURL()+":listVersions", query, nil, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *restClient) do(ctx context.Context, method, rawURL string, query url.Values, headers map[string]string, body, result interface{}) (http.Header, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		contents, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(contents)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	if len(query) != 0 {
+		rawURL += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := &APIError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Message:    string(contents),
+			RetryAfter: parseRetryAfter(response.Header.Get("Retry-After")),
+		}
+		errorBody := struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}{}
+		if json.Unmarshal(contents, &errorBody) == nil && errorBody.Error.Message != "" {
+			apiErr.Status, apiErr.Message = errorBody.Error.Status, errorBody.Error.Message
+		}
+		return nil, apiErr
+	}
+	return response.Header, json.Unmarshal(contents, result)
+}
@@ -0,0 +1,99 @@
+package firebase
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/emulator"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RestClientTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	client *restClient
+}
+
+func (c *RestClientTestSuite) SetupTest() {
+	c.server = httptest.NewServer(emulator.NewServer(afero.NewMemMapFs(), "/data"))
+	c.client = newRestClient(c.server.URL, DefaultEmulatorProject)
+}
+
+func (c *RestClientTestSuite) TearDownTest() {
+	c.server.Close()
+}
+
+func (c *RestClientTestSuite) publish(etag, value string) (*remoteconfig.Template, error) {
+	return c.client.PublishTemplate(context.Background(), remoteconfig.Template{
+		ETag: etag,
+		Parameters: map[string]remoteconfig.Parameter{
+			"key": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: value}},
+		},
+		Version: remoteconfig.Version{Description: "publish " + value},
+	}, false)
+}
+
+func (c *RestClientTestSuite) TestEmptyProjectHasNoParameters() {
+	response, err := c.client.GetRemoteConfig(context.Background(), "")
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), response.Parameters)
+	assert.Equal(c.T(), "etag-local-0", response.Etag)
+}
+
+func (c *RestClientTestSuite) TestPublishCreatesVersions() {
+	published, err := c.publish("", "1")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), int64(1), published.Version.VersionNumber)
+	_, err = c.publish(published.ETag, "2")
+	assert.NoError(c.T(), err)
+
+	response, err := c.client.GetRemoteConfig(context.Background(), "")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "2", response.Parameters["key"].DefaultValue.ExplicitValue)
+	response, err = c.client.GetRemoteConfig(context.Background(), "1")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "1", response.Parameters["key"].DefaultValue.ExplicitValue)
+
+	versions, err := c.client.ListVersions(context.Background(), &remoteconfig.ListVersionsOptions{PageSize: 1})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "publish 2", versions.Versions[0].Description)
+	assert.Equal(c.T(), "1", versions.NextPageToken)
+}
+
+func (c *RestClientTestSuite) TestPublishWithStaleEtagFails() {
+	published, err := c.publish("", "1")
+	assert.NoError(c.T(), err)
+	_, err = c.publish(published.ETag, "2")
+	assert.NoError(c.T(), err)
+	_, err = c.publish(published.ETag, "3")
+	apiErr, ok := err.(*APIError)
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), 412, apiErr.StatusCode)
+}
+
+func (c *RestClientTestSuite) TestValidateOnlyDoesNotStore() {
+	_, err := c.client.PublishTemplate(context.Background(), remoteconfig.Template{
+		Parameters: map[string]remoteconfig.Parameter{"key": {}},
+	}, true)
+	assert.NoError(c.T(), err)
+	response, err := c.client.GetRemoteConfig(context.Background(), "")
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), response.Parameters)
+}
+
+func (c *RestClientTestSuite) TestUndefinedConditionIsRejected() {
+	_, err := c.client.PublishTemplate(context.Background(), remoteconfig.Template{
+		Parameters: map[string]remoteconfig.Parameter{"key": {
+			ConditionalValues: map[string]*remoteconfig.ParameterValue{"missing": {ExplicitValue: "1"}},
+		}},
+	}, true)
+	assert.EqualError(c.T(), err, "400 INVALID_ARGUMENT: invalid template: parameter key uses undefined condition missing")
+}
+
+func TestRestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(RestClientTestSuite))
+}
@@ -0,0 +1,220 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/errorutils"
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+)
+
+// RetryOptions bounds the remote calls of a ClientStore. Zero fields take the value of DefaultRetryOptions.
+type RetryOptions struct {
+	// Timeout bounds every attempt of a call.
+	Timeout time.Duration
+	// MaxRetries is the number of times a failed call is retried. Negative disables retries.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, doubled for every following one up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryOptions are the options used for the fields left zero.
+var DefaultRetryOptions = RetryOptions{
+	Timeout:        30 * time.Second,
+	MaxRetries:     4,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.Timeout == 0 {
+		o.Timeout = DefaultRetryOptions.Timeout
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultRetryOptions.MaxRetries
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.InitialBackoff == 0 {
+		o.InitialBackoff = DefaultRetryOptions.InitialBackoff
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = DefaultRetryOptions.MaxBackoff
+	}
+	return o
+}
+
+// QuotaError is returned when Remote Config keeps rejecting calls because the quota of the project is exhausted.
+type QuotaError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("remote config quota exceeded after %d attempts, wait before applying again or request a higher quota: %s", e.Attempts, e.Err.Error())
+}
+
+// retryClient is a ConfigClient that times out the calls of client and retries them on transient errors.
+type retryClient struct {
+	client ConfigClient
+	opts   RetryOptions
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryClient(client ConfigClient, opts RetryOptions) *retryClient {
+	return &retryClient{client: client, opts: opts.withDefaults(), sleep: sleep}
+}
+
+func (c *retryClient) GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error) {
+	var response *remoteconfig.Response
+	err := c.do(ctx, true, func(ctx context.Context) (err error) {
+		response, err = c.client.GetRemoteConfig(ctx, versionNumber)
+		return err
+	})
+	return response, err
+}
+
+func (c *retryClient) PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error) {
+	var published *remoteconfig.Template
+	err := c.do(ctx, validateOnly, func(ctx context.Context) (err error) {
+		published, err = c.client.PublishTemplate(ctx, template, validateOnly)
+		return err
+	})
+	return published, err
+}
+
+func (c *retryClient) ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error) {
+	var response *remoteconfig.ListVersionsResponse
+	err := c.do(ctx, true, func(ctx context.Context) (err error) {
+		response, err = c.client.ListVersions(ctx, options)
+		return err
+	})
+	return response, err
+}
+
+// do runs call until it succeeds, fails with an error that is not retried, or runs out of retries.
+func (c *retryClient) do(ctx context.Context, idempotent bool, call func(ctx context.Context) error) error {
+	backoff := c.opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		err := call(attemptCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(err, context.DeadlineExceeded) || attemptCtx.Err() == context.DeadlineExceeded {
+			err = &timeoutError{timeout: c.opts.Timeout}
+		}
+		quota := isQuotaError(err)
+		if !quota && !(idempotent && isTransient(err)) {
+			return err
+		}
+		if attempt > c.opts.MaxRetries {
+			if quota {
+				return &QuotaError{Attempts: attempt, Err: err}
+			}
+			return fmt.Errorf("%s (after %d attempts)", err.Error(), attempt)
+		}
+		delay := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		if retryAfter := retryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return err
+		}
+		backoff *= 2
+		if backoff > c.opts.MaxBackoff {
+			backoff = c.opts.MaxBackoff
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// timeoutError is returned for an attempt that got no response within the timeout.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("no response within %s", e.timeout)
+}
+
+func isQuotaError(err error) bool {
+	return statusCode(err) == http.StatusTooManyRequests || errorutils.IsResourceExhausted(err)
+}
+
+// isTransient tells whether a call that failed with err may succeed when it is made again.
+func isTransient(err error) bool {
+	switch statusCode(err) {
+	case 0:
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+	if errorutils.IsUnavailable(err) || errorutils.IsInternal(err) || errorutils.IsDeadlineExceeded(err) {
+		return true
+	}
+	var timeoutErr *timeoutError
+	var netErr net.Error
+	return errors.As(err, &timeoutErr) || errors.As(err, &netErr)
+}
+
+// statusCode returns the HTTP status of the response err was caused by, or 0.
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	if response := errorutils.HTTPResponse(err); response != nil {
+		return response.StatusCode
+	}
+	return 0
+}
+
+// retryAfter returns the delay the response err was caused by asked for, or 0.
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	if response := errorutils.HTTPResponse(err); response != nil {
+		return parseRetryAfter(response.Header.Get("Retry-After"))
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as a date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && time.Until(date) > 0 {
+		return time.Until(date)
+	}
+	return 0
+}
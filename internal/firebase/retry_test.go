@@ -0,0 +1,176 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// scriptedClient fails the first calls with the scripted errors and then succeeds.
+type scriptedClient struct {
+	errs  []error
+	calls int
+	// block makes every call wait until its context is done.
+	block bool
+}
+
+func (c *scriptedClient) next(ctx context.Context) error {
+	c.calls++
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if c.calls <= len(c.errs) {
+		return c.errs[c.calls-1]
+	}
+	return nil
+}
+
+func (c *scriptedClient) GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error) {
+	if err := c.next(ctx); err != nil {
+		return nil, err
+	}
+	return &remoteconfig.Response{RemoteConfig: &remoteconfig.RemoteConfig{}}, nil
+}
+
+func (c *scriptedClient) PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error) {
+	if err := c.next(ctx); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (c *scriptedClient) ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error) {
+	if err := c.next(ctx); err != nil {
+		return nil, err
+	}
+	return &remoteconfig.ListVersionsResponse{}, nil
+}
+
+type RetryTestSuite struct {
+	suite.Suite
+	delays []time.Duration
+}
+
+func TestRetry(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
+
+func (c *RetryTestSuite) SetupTest() {
+	c.delays = nil
+}
+
+func (c *RetryTestSuite) retryClient(client ConfigClient, opts RetryOptions) *retryClient {
+	retry := newRetryClient(client, opts)
+	retry.sleep = func(ctx context.Context, d time.Duration) error {
+		c.delays = append(c.delays, d)
+		return ctx.Err()
+	}
+	return retry
+}
+
+func unavailable() error {
+	return &APIError{StatusCode: http.StatusServiceUnavailable, Status: "UNAVAILABLE", Message: "try again"}
+}
+
+func (c *RetryTestSuite) TestReadsAreRetriedWithBackoff() {
+	client := &scriptedClient{errs: []error{unavailable(), unavailable(), unavailable()}}
+	_, err := c.retryClient(client, RetryOptions{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}).GetRemoteConfig(context.Background(), "")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), 4, client.calls)
+	assert.Len(c.T(), c.delays, 3)
+	for i, base := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		assert.True(c.T(), c.delays[i] >= base && c.delays[i] <= base+base/2, "delay %d is %s", i, c.delays[i])
+	}
+}
+
+func (c *RetryTestSuite) TestRetriesRunOut() {
+	client := &scriptedClient{errs: []error{unavailable(), unavailable(), unavailable()}}
+	_, err := c.retryClient(client, RetryOptions{MaxRetries: 2}).ListVersions(context.Background(), &remoteconfig.ListVersionsOptions{})
+	assert.EqualError(c.T(), err, "503 UNAVAILABLE: try again (after 3 attempts)")
+	assert.Equal(c.T(), 3, client.calls)
+
+	client = &scriptedClient{errs: []error{unavailable()}}
+	_, err = c.retryClient(client, RetryOptions{MaxRetries: -1}).ListVersions(context.Background(), &remoteconfig.ListVersionsOptions{})
+	assert.Error(c.T(), err)
+	assert.Equal(c.T(), 1, client.calls)
+}
+
+func (c *RetryTestSuite) TestPermanentErrorsAreNotRetried() {
+	client := &scriptedClient{errs: []error{&APIError{StatusCode: http.StatusBadRequest, Status: "INVALID_ARGUMENT", Message: "bad template"}}}
+	_, err := c.retryClient(client, RetryOptions{}).GetRemoteConfig(context.Background(), "")
+	assert.EqualError(c.T(), err, "400 INVALID_ARGUMENT: bad template")
+	assert.Equal(c.T(), 1, client.calls)
+}
+
+func (c *RetryTestSuite) TestPublishIsOnlyRetriedOnQuotaErrors() {
+	client := &scriptedClient{errs: []error{unavailable()}}
+	_, err := c.retryClient(client, RetryOptions{}).PublishTemplate(context.Background(), remoteconfig.Template{}, false)
+	assert.Error(c.T(), err)
+	assert.Equal(c.T(), 1, client.calls)
+
+	client = &scriptedClient{errs: []error{unavailable()}}
+	_, err = c.retryClient(client, RetryOptions{}).PublishTemplate(context.Background(), remoteconfig.Template{}, true)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), 2, client.calls)
+
+	quota := &APIError{StatusCode: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED", Message: "quota exceeded", RetryAfter: time.Minute}
+	c.delays = nil
+	client = &scriptedClient{errs: []error{quota}}
+	_, err = c.retryClient(client, RetryOptions{}).PublishTemplate(context.Background(), remoteconfig.Template{}, false)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), 2, client.calls)
+	assert.Equal(c.T(), []time.Duration{time.Minute}, c.delays)
+
+	client = &scriptedClient{errs: []error{quota, quota}}
+	_, err = c.retryClient(client, RetryOptions{MaxRetries: 1}).PublishTemplate(context.Background(), remoteconfig.Template{}, false)
+	quotaErr := &QuotaError{}
+	assert.True(c.T(), errors.As(err, &quotaErr))
+	assert.Equal(c.T(), 2, quotaErr.Attempts)
+}
+
+func (c *RetryTestSuite) TestAttemptsTimeOut() {
+	client := &scriptedClient{block: true}
+	_, err := c.retryClient(client, RetryOptions{Timeout: 10 * time.Millisecond, MaxRetries: 1}).GetRemoteConfig(context.Background(), "")
+	assert.EqualError(c.T(), err, "no response within 10ms (after 2 attempts)")
+	assert.Equal(c.T(), 2, client.calls)
+}
+
+func (c *RetryTestSuite) TestCancellationStopsRetries() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	client := &scriptedClient{block: true}
+	_, err := c.retryClient(client, RetryOptions{}).GetRemoteConfig(ctx, "")
+	assert.Equal(c.T(), context.Canceled, err)
+	assert.Equal(c.T(), 1, client.calls)
+	assert.Empty(c.T(), c.delays)
+}
+
+func (c *RetryTestSuite) TestParseRetryAfter() {
+	assert.Equal(c.T(), 30*time.Second, parseRetryAfter("30"))
+	assert.Equal(c.T(), time.Duration(0), parseRetryAfter(""))
+	assert.Equal(c.T(), time.Duration(0), parseRetryAfter("soon"))
+	later := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(c.T(), later > 59*time.Minute && later <= time.Hour)
+}
+
+func (c *RetryTestSuite) TestAbandonedSdkCalls() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	release := make(chan struct{})
+	defer close(release)
+	_, err := abandonable(ctx, func() (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	assert.Equal(c.T(), context.Canceled, err)
+}
@@ -0,0 +1,77 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+)
+
+// sdkClient is a ConfigClient calling Remote Config through the Firebase Admin SDK.
+type sdkClient struct {
+	client *remoteconfig.Client
+}
+
+func (c *sdkClient) GetRemoteConfig(ctx context.Context, versionNumber string) (*remoteconfig.Response, error) {
+	result, err := abandonable(ctx, func() (interface{}, error) {
+		return c.client.GetRemoteConfig(versionNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*remoteconfig.Response), nil
+}
+
+// PublishTemplate publishes template, only when the live template still has its ETag if it has one.
+func (c *sdkClient) PublishTemplate(ctx context.Context, template remoteconfig.Template, validateOnly bool) (*remoteconfig.Template, error) {
+	if template.ETag != "" && !validateOnly {
+		live, err := c.GetRemoteConfig(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		if live.Etag != template.ETag {
+			return nil, &APIError{
+				StatusCode: http.StatusPreconditionFailed,
+				Status:     "FAILED_PRECONDITION",
+				Message:    fmt.Sprintf("the live template has ETag %s, expected %s", live.Etag, template.ETag),
+			}
+		}
+	}
+	result, err := abandonable(ctx, func() (interface{}, error) {
+		return c.client.PublishTemplate(ctx, template, validateOnly)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*remoteconfig.Template), nil
+}
+
+func (c *sdkClient) ListVersions(ctx context.Context, options *remoteconfig.ListVersionsOptions) (*remoteconfig.ListVersionsResponse, error) {
+	result, err := abandonable(ctx, func() (interface{}, error) {
+		return c.client.ListVersions(options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*remoteconfig.ListVersionsResponse), nil
+}
+
+// abandonable runs call, returning early with the error of ctx when it is done first.
+func abandonable(ctx context.Context, call func() (interface{}, error)) (interface{}, error) {
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := call()
+		done <- outcome{result, err}
+	}()
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
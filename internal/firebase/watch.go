@@ -0,0 +1,50 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/spf13/afero"
+)
+
+// sourcesFingerprint summarizes the name, size and modification time of every source file in dir.
+func (cs *ClientStore) sourcesFingerprint(dir string) string {
+	sb := strings.Builder{}
+	for _, sourceDir := range []string{config.ConditionsDir, config.ParametersDir, config.SecretParametersDir} {
+		afero.Walk(cs.customFs.fs, filepath.Join(dir, sourceDir), func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			sb.WriteString(fmt.Sprintf("%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano()))
+			return nil
+		})
+	}
+	if info, err := cs.customFs.fs.Stat(filepath.Join(dir, config.ToolConfigFile)); err == nil {
+		sb.WriteString(fmt.Sprintf("%s:%d:%d\n", config.ToolConfigFile, info.Size(), info.ModTime().UnixNano()))
+	}
+	return sb.String()
+}
+
+// WatchLocalConfig polls the sources in dir every interval and calls onChange when they change, until ctx is done.
+func (cs *ClientStore) WatchLocalConfig(ctx context.Context, dir string, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := cs.sourcesFingerprint(dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := cs.sourcesFingerprint(dir)
+			if current != last {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
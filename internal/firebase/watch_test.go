@@ -0,0 +1,59 @@
+package firebase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchTestSuite struct {
+	suite.Suite
+	fs afero.Fs
+	cs *ClientStore
+}
+
+func TestWatch(t *testing.T) {
+	suite.Run(t, new(WatchTestSuite))
+}
+
+func (c *WatchTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	c.cs = &ClientStore{customFs: &customFs{fs: c.fs}}
+	afero.WriteFile(c.fs, "/src/parameters/parameters.json", []byte(`{}`), 0644)
+	afero.WriteFile(c.fs, "/src/conditions/conditions.json", []byte(`[]`), 0644)
+}
+
+func (c *WatchTestSuite) TestFingerprintChangesWithSources() {
+	before := c.cs.sourcesFingerprint("/src")
+	assert.Equal(c.T(), before, c.cs.sourcesFingerprint("/src"))
+
+	afero.WriteFile(c.fs, "/src/parameters/parameters.json", []byte(`{"key": {}}`), 0644)
+	changed := c.cs.sourcesFingerprint("/src")
+	assert.NotEqual(c.T(), before, changed)
+
+	afero.WriteFile(c.fs, "/src/secret-parameters/parameters.json", []byte(`{}`), 0644)
+	assert.NotEqual(c.T(), changed, c.cs.sourcesFingerprint("/src"))
+}
+
+func (c *WatchTestSuite) TestWatchCallsOnChange() {
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		c.cs.WatchLocalConfig(ctx, "/src", time.Millisecond, func() { changes <- struct{}{} })
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	afero.WriteFile(c.fs, "/src/conditions/conditions.json", []byte(`[{"name": "a"}]`), 0644)
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		c.T().Fatal("change was not detected")
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotation is a message GitHub Actions shows on a line of a file in the workflow run and the pull request.
+type Annotation struct {
+	// Level is error, warning or notice.
+	Level   string
+	File    string
+	Line    int
+	Column  int
+	Title   string
+	Message string
+}
+
+// String returns the workflow command that creates the annotation.
+func (a Annotation) String() string {
+	properties := []string{}
+	if a.File != "" {
+		properties = append(properties, "file="+escapeProperty(a.File))
+		if a.Line != 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", a.Line))
+		}
+		if a.Column != 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", a.Column))
+		}
+	}
+	if a.Title != "" {
+		properties = append(properties, "title="+escapeProperty(a.Title))
+	}
+	command := "::" + a.Level
+	if len(properties) != 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	return command + "::" + escapeData(a.Message)
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,176 @@
+// Package github posts results to pull requests through the GitHub REST API, and formats them as workflow commands
+// for GitHub Actions.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultAPIURL is the GitHub REST API used when GITHUB_API_URL is not set.
+const DefaultAPIURL = "https://api.github.com"
+
+// commentsPerPage is the page size used to search the comments of a pull request.
+const commentsPerPage = 100
+
+// MaxCommentLength is the number of characters GitHub accepts in the body of a comment.
+const MaxCommentLength = 65536
+
+// truncatedNote ends a comment body cut to MaxCommentLength.
+const truncatedNote = "\n\n_Diff truncated, the comment exceeded the length GitHub accepts._\n"
+
+// Client talks to the GitHub REST API for one repository.
+type Client struct {
+	apiURL     string
+	token      string
+	repository string
+	httpClient *http.Client
+}
+
+// APIError is an error response of the GitHub API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github api returned %d: %s", e.StatusCode, e.Message)
+}
+
+// NewClient returns a Client for repository, given as owner/name.
+func NewClient(apiURL, token, repository string) *Client {
+	return &Client{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		repository: repository,
+		httpClient: &http.Client{},
+	}
+}
+
+// NewClientFromEnv returns a Client configured the way GitHub Actions sets up a job: the token in GITHUB_TOKEN, the
+// repository in GITHUB_REPOSITORY and, on GitHub Enterprise, the API in GITHUB_API_URL.
+func NewClientFromEnv() (*Client, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN is not set")
+	}
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if strings.Count(repository, "/") != 1 {
+		return nil, fmt.Errorf("GITHUB_REPOSITORY must be set to owner/name, got %q", repository)
+	}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return NewClient(apiURL, token, repository), nil
+}
+
+// Comment is an issue or pull request comment.
+type Comment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// UpsertComment keeps a single comment identified by marker on pull request number: the first comment containing
+// marker is replaced with body, and a new one is created when there is none. marker is appended to body so that the
+// next run finds the comment again, and should be an HTML comment so that it is not shown. A body too long for
+// GitHub is truncated with a note.
+func (c *Client) UpsertComment(ctx context.Context, number int, marker, body string) (*Comment, error) {
+	body = fitComment(body, "\n"+marker+"\n")
+	existing, err := c.findComment(ctx, number, marker)
+	if err != nil {
+		return nil, err
+	}
+	comment := &Comment{}
+	if existing == nil {
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments", c.repository, number)
+		err = c.do(ctx, http.MethodPost, path, map[string]string{"body": body}, comment)
+	} else {
+		path := fmt.Sprintf("/repos/%s/issues/comments/%d", c.repository, existing.ID)
+		err = c.do(ctx, http.MethodPatch, path, map[string]string{"body": body}, comment)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// fitComment returns body followed by suffix, with body cut so that the result has at most MaxCommentLength
+// characters.
+func fitComment(body, suffix string) string {
+	length := utf8.RuneCountInString(body) + utf8.RuneCountInString(suffix)
+	if length <= MaxCommentLength {
+		return body + suffix
+	}
+	keep := MaxCommentLength - utf8.RuneCountInString(suffix) - utf8.RuneCountInString(truncatedNote)
+	return string([]rune(body)[:keep]) + truncatedNote + suffix
+}
+
+// findComment returns the first comment on pull request number containing marker, or nil.
+func (c *Client) findComment(ctx context.Context, number int, marker string) (*Comment, error) {
+	for page := 1; ; page++ {
+		comments := []Comment{}
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=%d&page=%d", c.repository, number, commentsPerPage, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			if strings.Contains(comments[i].Body, marker) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < commentsPerPage {
+			return nil, nil
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		contents, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(contents)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: response.StatusCode, Message: string(contents)}
+		errorBody := struct {
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(contents, &errorBody) == nil && errorBody.Message != "" {
+			apiErr.Message = errorBody.Message
+		}
+		return apiErr
+	}
+	return json.Unmarshal(contents, result)
+}
@@ -0,0 +1,148 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeGitHub stands in for the issue comment endpoints of the GitHub API.
+type fakeGitHub struct {
+	comments []Comment
+	nextID   int64
+	requests []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "Bad credentials"}`)
+		return
+	}
+	body := struct {
+		Body string `json:"body"`
+	}{}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/app/issues/7/comments":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		start, end := (page-1)*perPage, page*perPage
+		if start > len(f.comments) {
+			start = len(f.comments)
+		}
+		if end > len(f.comments) {
+			end = len(f.comments)
+		}
+		json.NewEncoder(w).Encode(f.comments[start:end])
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/app/issues/7/comments":
+		json.NewDecoder(r.Body).Decode(&body)
+		f.nextID++
+		f.comments = append(f.comments, Comment{ID: f.nextID, Body: body.Body})
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.comments[len(f.comments)-1])
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/acme/app/issues/comments/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/repos/acme/app/issues/comments/"), 10, 64)
+		json.NewDecoder(r.Body).Decode(&body)
+		for i := range f.comments {
+			if f.comments[i].ID == id {
+				f.comments[i].Body = body.Body
+				json.NewEncoder(w).Encode(f.comments[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type GitHubTestSuite struct {
+	suite.Suite
+	fake   *fakeGitHub
+	server *httptest.Server
+}
+
+func TestGitHub(t *testing.T) {
+	suite.Run(t, new(GitHubTestSuite))
+}
+
+func (c *GitHubTestSuite) SetupTest() {
+	c.fake = &fakeGitHub{nextID: 1000}
+	c.server = httptest.NewServer(c.fake)
+}
+
+func (c *GitHubTestSuite) TearDownTest() {
+	c.server.Close()
+}
+
+func (c *GitHubTestSuite) TestUpsertCommentCreatesThenUpdates() {
+	for i := 0; i < commentsPerPage+5; i++ {
+		c.fake.comments = append(c.fake.comments, Comment{ID: int64(i + 1), Body: "looks good"})
+	}
+	client := NewClient(c.server.URL+"/", "secret", "acme/app")
+	marker := "<!-- firebase-ctl diff -->"
+
+	created, err := client.UpsertComment(context.Background(), 7, marker, "first")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), int64(1001), created.ID)
+	assert.Equal(c.T(), "first\n"+marker+"\n", created.Body)
+
+	updated, err := client.UpsertComment(context.Background(), 7, marker, "second")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), int64(1001), updated.ID)
+	assert.Len(c.T(), c.fake.comments, commentsPerPage+6)
+	assert.Equal(c.T(), "second\n"+marker+"\n", c.fake.comments[commentsPerPage+5].Body)
+	assert.Equal(c.T(), "PATCH /repos/acme/app/issues/comments/1001", c.fake.requests[len(c.fake.requests)-1])
+}
+
+func (c *GitHubTestSuite) TestUpsertCommentTruncatesLongBodies() {
+	client := NewClient(c.server.URL, "secret", "acme/app")
+	marker := "<!-- firebase-ctl diff -->"
+	created, err := client.UpsertComment(context.Background(), 7, marker, strings.Repeat("é", MaxCommentLength))
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), MaxCommentLength, utf8.RuneCountInString(created.Body))
+	assert.True(c.T(), strings.HasSuffix(created.Body, truncatedNote+"\n"+marker+"\n"))
+}
+
+func (c *GitHubTestSuite) TestAPIErrors() {
+	client := NewClient(c.server.URL, "wrong", "acme/app")
+	_, err := client.UpsertComment(context.Background(), 7, "<!-- m -->", "body")
+	assert.EqualError(c.T(), err, "github api returned 401: Bad credentials")
+}
+
+func (c *GitHubTestSuite) TestNewClientFromEnv() {
+	for _, key := range []string{"GITHUB_TOKEN", "GITHUB_REPOSITORY", "GITHUB_API_URL"} {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+	os.Setenv("GITHUB_TOKEN", "")
+	_, err := NewClientFromEnv()
+	assert.EqualError(c.T(), err, "GITHUB_TOKEN is not set")
+
+	os.Setenv("GITHUB_TOKEN", "secret")
+	os.Setenv("GITHUB_REPOSITORY", "app")
+	_, err = NewClientFromEnv()
+	assert.Error(c.T(), err)
+
+	os.Setenv("GITHUB_REPOSITORY", "acme/app")
+	os.Setenv("GITHUB_API_URL", "")
+	client, err := NewClientFromEnv()
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), DefaultAPIURL, client.apiURL)
+}
+
+func (c *GitHubTestSuite) TestAnnotation() {
+	annotation := Annotation{Level: "error", File: "config/a,b.json", Line: 3, Column: 7, Title: "validation", Message: "50% bad\nvalue"}
+	assert.Equal(c.T(), "::error file=config/a%2Cb.json,line=3,col=7,title=validation::50%25 bad%0Avalue", annotation.String())
+	assert.Equal(c.T(), "::warning::plain", Annotation{Level: "warning", Message: "plain"}.String())
+}
@@ -0,0 +1,135 @@
+package jsonpos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Error is an error at a line and column of a document. Both start at 1 and columns are counted in UTF-16 code
+// units, as editors and SARIF viewers expect.
+type Error struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Err.Error())
+}
+
+// Location converts an offset to a line and a column starting at 1.
+func (d *Document) Location(offset int) (line, column int) {
+	line, column = d.Position(offset)
+	return line + 1, column + 1
+}
+
+// errorAt places err at offset.
+func (d *Document) errorAt(offset int, err error) *Error {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(d.data) {
+		offset = len(d.data)
+	}
+	line, column := d.Location(offset)
+	return &Error{Line: line, Column: column, Err: err}
+}
+
+// Decode decodes the first JSON value in data into v as a json.Decoder does, except that syntax errors, values of
+// the wrong type and duplicate object keys are returned as an *Error placed where they occur.
+func Decode(data []byte, v interface{}) error {
+	err := json.NewDecoder(bytes.NewReader(data)).Decode(v)
+	if err == io.ErrUnexpectedEOF {
+		return Parse(data).errorAt(len(data), err)
+	}
+	switch e := err.(type) {
+	case nil:
+	case *json.SyntaxError:
+		doc := Parse(data)
+		offset := int(e.Offset) - 1
+		if syntaxErr, ok := doc.Err.(*SyntaxError); ok {
+			offset = syntaxErr.Offset
+		}
+		return doc.errorAt(offset, err)
+	case *json.UnmarshalTypeError:
+		doc := Parse(data)
+		offset := int(e.Offset)
+		if node, ok := doc.At(offset); ok {
+			offset = node.Value.Start
+		}
+		return doc.errorAt(offset, err)
+	default:
+		return err
+	}
+	doc := Parse(data)
+	if duplicates := doc.Duplicates(); len(duplicates) != 0 {
+		path := duplicates[0].Path
+		return doc.errorAt(duplicates[0].Key.Start, fmt.Errorf("duplicate key %s", path[len(path)-1]))
+	}
+	return nil
+}
+
+// Duplicates returns the object members whose key was already used earlier in the same object.
+func (d *Document) Duplicates() []Node {
+	seen := map[string]bool{}
+	duplicates := []Node{}
+	for _, node := range d.Nodes {
+		if node.Key.End == 0 {
+			continue
+		}
+		path := strings.Join(node.Path, "\x00")
+		if seen[path] {
+			duplicates = append(duplicates, node)
+		}
+		seen[path] = true
+	}
+	return duplicates
+}
+
+// StringOffset returns the offset in the document of the byte at offset decoded of the string at span. A decoded
+// byte that comes from an escape sequence or from a multi-byte character maps to the start of it.
+func (d *Document) StringOffset(span Span, decoded int) int {
+	end := span.End - 1
+	pos := span.Start + 1
+	for pos < end {
+		size, width := 1, 1
+		if d.data[pos] >= utf8.RuneSelf {
+			_, size = utf8.DecodeRune(d.data[pos:end])
+			width = size
+		}
+		if d.data[pos] == '\\' && pos+1 < end {
+			size = 2
+			if d.data[pos+1] == 'u' && pos+6 <= end {
+				size = 6
+				r := hexRune(d.data[pos+2 : pos+6])
+				if utf16.IsSurrogate(r) && pos+12 <= end && d.data[pos+6] == '\\' && d.data[pos+7] == 'u' {
+					r = utf16.DecodeRune(r, hexRune(d.data[pos+8:pos+12]))
+					size = 12
+				}
+				if width = utf8.RuneLen(r); width < 0 {
+					width = utf8.RuneLen(utf8.RuneError)
+				}
+			}
+		}
+		if decoded < width {
+			return pos
+		}
+		decoded -= width
+		pos += size
+	}
+	return pos
+}
+
+func hexRune(digits []byte) rune {
+	r, err := strconv.ParseUint(string(digits), 16, 32)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(r)
+}
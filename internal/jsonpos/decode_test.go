@@ -0,0 +1,63 @@
+package jsonpos
+
+import (
+	"io"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (c *JsonPosTestSuite) TestDecodePlacesSyntaxErrors() {
+	var v map[string]interface{}
+	err := Decode([]byte("{\n  \"a\": 1,\n  \"b\": ]\n}"), &v)
+	assert.IsType(c.T(), &Error{}, err)
+	assert.Equal(c.T(), 3, err.(*Error).Line)
+	assert.Equal(c.T(), 8, err.(*Error).Column)
+	assert.True(c.T(), strings.HasPrefix(err.Error(), "3:8: invalid character ']'"))
+
+	err = Decode([]byte("{\n  \"a\": "), &v)
+	assert.Equal(c.T(), 2, err.(*Error).Line)
+
+	assert.Equal(c.T(), io.EOF, Decode([]byte(""), &v))
+}
+
+func (c *JsonPosTestSuite) TestDecodePlacesTypeErrors() {
+	var v map[string]struct{ Value string }
+	err := Decode([]byte("{\n  \"a\": {\"Value\": 12}\n}"), &v)
+	assert.Equal(c.T(), 2, err.(*Error).Line)
+	assert.Equal(c.T(), 18, err.(*Error).Column)
+}
+
+func (c *JsonPosTestSuite) TestDecodeRejectsDuplicateKeys() {
+	var v map[string]interface{}
+	err := Decode([]byte("{\n  \"a\": 1,\n  \"b\": {\"c\": 1, \"c\": 2},\n  \"a\": 3\n}"), &v)
+	assert.EqualError(c.T(), err, "3:17: duplicate key c")
+
+	var list []interface{}
+	assert.NoError(c.T(), Decode([]byte(`[{"a": 1}, {"a": 2}]`), &list))
+}
+
+func (c *JsonPosTestSuite) TestStringOffset() {
+	data := `{"v": "{\"a\": ü😀x}"}`
+	doc := Parse([]byte(data))
+	node, _ := doc.Lookup("v")
+	decoded, _ := doc.String(node.Value)
+	assert.Equal(c.T(), "{\"a\": ü😀x}", decoded)
+
+	for _, tc := range []struct {
+		decoded int
+		raw     string
+	}{
+		{0, `{`},
+		{1, `\"a`},
+		{2, `a`},
+		{6, `ü`},
+		{7, `ü`},
+		{8, `😀`},
+		{12, `x`},
+		{13, `}`},
+	} {
+		offset := doc.StringOffset(node.Value, tc.decoded)
+		assert.True(c.T(), strings.HasPrefix(data[offset:], tc.raw), "decoded offset %d maps to %q", tc.decoded, data[offset:])
+	}
+}
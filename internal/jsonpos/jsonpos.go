@@ -0,0 +1,340 @@
+// Package jsonpos maps the keys and values of a JSON document to their positions in the source, so that problems
+// found in decoded values can be reported at the place they come from. Documents being edited are often not valid
+// JSON, so the scanner keeps everything it could read before the first syntax error.
+package jsonpos
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Span is a range of byte offsets in a document.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Contains tells whether offset is inside the span or right after it.
+func (s Span) Contains(offset int) bool {
+	return offset >= s.Start && offset <= s.End
+}
+
+// Node is a value in the document. Path holds the object keys and array indexes leading to it; Key is the span of
+// the quoted key for object members and empty otherwise.
+type Node struct {
+	Path  []string
+	Key   Span
+	Value Span
+}
+
+// Document is an indexed JSON document.
+type Document struct {
+	data  []byte
+	Nodes []Node
+	// Err is the first syntax error, if any.
+	Err        error
+	lineStarts []int
+}
+
+// Context is the place of an offset in the document, as needed for completion.
+type Context struct {
+	// Path of the innermost object or array containing the offset.
+	Path []string
+	// InKey is set when the offset is where a member key of that object is written.
+	InKey bool
+}
+
+// SyntaxError is a syntax error at an offset.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// Parse indexes data.
+func Parse(data []byte) *Document {
+	s := &scanner{data: data, until: -1}
+	s.value(nil, Span{})
+	s.space()
+	if s.err == nil && s.pos < len(data) {
+		s.fail("invalid character " + strconv.QuoteRune(rune(data[s.pos])) + " after top-level value")
+	}
+	d := &Document{data: data, Nodes: s.nodes, lineStarts: []int{0}}
+	if s.err != nil {
+		d.Err = s.err
+	}
+	for i, b := range data {
+		if b == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+// ContextAt returns the place of offset in data.
+func ContextAt(data []byte, offset int) Context {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	s := &scanner{data: data[:offset], until: offset}
+	s.value(nil, Span{})
+	return s.context
+}
+
+// Lookup returns the node at path.
+func (d *Document) Lookup(path ...string) (Node, bool) {
+	for _, node := range d.Nodes {
+		if equalPaths(node.Path, path) {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+// At returns the innermost node whose key or value contains offset.
+func (d *Document) At(offset int) (Node, bool) {
+	found, ok := Node{}, false
+	for _, node := range d.Nodes {
+		if (node.Key.End > 0 && node.Key.Contains(offset)) || node.Value.Contains(offset) {
+			if !ok || len(node.Path) >= len(found.Path) {
+				found, ok = node, true
+			}
+		}
+	}
+	return found, ok
+}
+
+// Raw returns the source of a span.
+func (d *Document) Raw(span Span) []byte {
+	return d.data[span.Start:span.End]
+}
+
+// String returns the decoded string at span, or false if it is not a string.
+func (d *Document) String(span Span) (string, bool) {
+	var value string
+	if err := json.Unmarshal(d.Raw(span), &value); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// Position converts an offset to a zero based line and a zero based column counted in UTF-16 code units, as used by
+// the language server protocol.
+func (d *Document) Position(offset int) (line, column int) {
+	line = 0
+	for i, start := range d.lineStarts {
+		if start > offset {
+			break
+		}
+		line = i
+	}
+	for _, r := range string(d.data[d.lineStarts[line]:offset]) {
+		column += len(utf16.Encode([]rune{r}))
+	}
+	return line, column
+}
+
+// Offset converts a zero based line and UTF-16 column to an offset.
+func (d *Document) Offset(line, column int) int {
+	if line >= len(d.lineStarts) {
+		return len(d.data)
+	}
+	offset := d.lineStarts[line]
+	for column > 0 && offset < len(d.data) && d.data[offset] != '\n' {
+		r, size := utf8.DecodeRune(d.data[offset:])
+		column -= len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type scanner struct {
+	data  []byte
+	pos   int
+	nodes []Node
+	err   error
+	// until is the offset ContextAt looks for, or -1.
+	until   int
+	context Context
+}
+
+func (s *scanner) fail(msg string) {
+	if s.err == nil {
+		s.err = &SyntaxError{Offset: s.pos, Msg: msg}
+	}
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *scanner) space() {
+	for !s.eof() {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value scans the value at the current position and reports whether it was complete.
+func (s *scanner) value(path []string, key Span) bool {
+	s.space()
+	if s.eof() {
+		s.fail("unexpected end of JSON input")
+		return false
+	}
+	start := s.pos
+	complete := false
+	switch s.data[s.pos] {
+	case '{':
+		complete = s.object(path)
+	case '[':
+		complete = s.array(path)
+	case '"':
+		complete = s.str()
+	default:
+		complete = s.literal()
+	}
+	s.nodes = append(s.nodes, Node{Path: path, Key: key, Value: Span{Start: start, End: s.pos}})
+	return complete
+}
+
+func (s *scanner) object(path []string) bool {
+	s.pos++
+	for {
+		s.space()
+		if s.eof() {
+			s.context = Context{Path: path, InKey: true}
+			s.fail("unexpected end of JSON input")
+			return false
+		}
+		switch s.data[s.pos] {
+		case '}':
+			s.pos++
+			return true
+		case ',':
+			s.pos++
+			continue
+		case '"':
+		default:
+			s.fail("invalid character " + strconv.QuoteRune(rune(s.data[s.pos])) + " looking for beginning of object key string")
+			return false
+		}
+		keyStart := s.pos
+		if !s.str() {
+			s.context = Context{Path: path, InKey: true}
+			return false
+		}
+		keySpan := Span{Start: keyStart, End: s.pos}
+		var name string
+		json.Unmarshal(s.data[keyStart:s.pos], &name)
+		s.space()
+		if s.eof() {
+			s.context = Context{Path: path, InKey: true}
+			s.fail("unexpected end of JSON input")
+			return false
+		}
+		if s.data[s.pos] != ':' {
+			s.fail("invalid character " + strconv.QuoteRune(rune(s.data[s.pos])) + " after object key")
+			return false
+		}
+		s.pos++
+		childPath := append(append([]string{}, path...), name)
+		if !s.value(childPath, keySpan) {
+			if s.context.Path == nil {
+				s.context = Context{Path: path}
+			}
+			return false
+		}
+	}
+}
+
+func (s *scanner) array(path []string) bool {
+	s.pos++
+	for index := 0; ; {
+		s.space()
+		if s.eof() {
+			s.context = Context{Path: path}
+			s.fail("unexpected end of JSON input")
+			return false
+		}
+		switch s.data[s.pos] {
+		case ']':
+			s.pos++
+			return true
+		case ',':
+			s.pos++
+			continue
+		}
+		childPath := append(append([]string{}, path...), strconv.Itoa(index))
+		if !s.value(childPath, Span{}) {
+			if s.context.Path == nil {
+				s.context = Context{Path: path}
+			}
+			return false
+		}
+		index++
+	}
+}
+
+func (s *scanner) str() bool {
+	s.pos++
+	for !s.eof() {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return true
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.data)
+	s.fail("unexpected end of JSON input")
+	return false
+}
+
+func (s *scanner) literal() bool {
+	start := s.pos
+	for !s.eof() {
+		switch s.data[s.pos] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			return s.checkLiteral(start)
+		}
+		s.pos++
+	}
+	if s.until >= 0 {
+		return false
+	}
+	return s.checkLiteral(start)
+}
+
+func (s *scanner) checkLiteral(start int) bool {
+	if !json.Valid(s.data[start:s.pos]) {
+		s.pos = start
+		s.fail("invalid character " + strconv.QuoteRune(rune(s.data[start])) + " looking for beginning of value")
+		return false
+	}
+	return true
+}
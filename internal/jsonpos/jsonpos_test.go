@@ -0,0 +1,76 @@
+package jsonpos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type JsonPosTestSuite struct {
+	suite.Suite
+}
+
+func TestJsonPos(t *testing.T) {
+	suite.Run(t, new(JsonPosTestSuite))
+}
+
+const parameters = `{
+  "key": {
+    "defaultValue": {"value": "ü"},
+    "conditionalValues": {"ios": {"value": "1"}}
+  }
+}`
+
+func (c *JsonPosTestSuite) TestLookup() {
+	doc := Parse([]byte(parameters))
+	assert.NoError(c.T(), doc.Err)
+	node, ok := doc.Lookup("key", "conditionalValues", "ios")
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), `"ios"`, string(doc.Raw(node.Key)))
+	assert.Equal(c.T(), `{"value": "1"}`, string(doc.Raw(node.Value)))
+	line, column := doc.Position(node.Key.Start)
+	assert.Equal(c.T(), 3, line)
+	assert.Equal(c.T(), 26, column)
+	assert.Equal(c.T(), node.Key.Start, doc.Offset(line, column))
+
+	value, _ := doc.Lookup("key", "defaultValue", "value")
+	decoded, ok := doc.String(value.Value)
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), "ü", decoded)
+}
+
+func (c *JsonPosTestSuite) TestArrays() {
+	doc := Parse([]byte(`[{"name": "a"}, {"name": "b"}]`))
+	node, ok := doc.Lookup("1", "name")
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), `"b"`, string(doc.Raw(node.Value)))
+}
+
+func (c *JsonPosTestSuite) TestAt() {
+	doc := Parse([]byte(parameters))
+	node, ok := doc.At(strings.Index(parameters, `"ios"`) + 2)
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), []string{"key", "conditionalValues", "ios"}, node.Path)
+}
+
+func (c *JsonPosTestSuite) TestSyntaxErrorKeepsPrefix() {
+	doc := Parse([]byte(`{"a": 1, "b": tru}`))
+	err, ok := doc.Err.(*SyntaxError)
+	assert.True(c.T(), ok)
+	assert.Equal(c.T(), 14, err.Offset)
+	_, ok = doc.Lookup("a")
+	assert.True(c.T(), ok)
+}
+
+func (c *JsonPosTestSuite) TestContextAt() {
+	source := `{"key": {"conditionalValues": {"io`
+	assert.Equal(c.T(), Context{Path: []string{"key", "conditionalValues"}, InKey: true}, ContextAt([]byte(source), len(source)))
+
+	source = `{"key": {"conditionalValues": {"ios": {"value": "x`
+	assert.Equal(c.T(), Context{Path: []string{"key", "conditionalValues", "ios"}}, ContextAt([]byte(source), len(source)))
+
+	source = `{"key": {"conditionalValues": {"ios": {}, `
+	assert.Equal(c.T(), Context{Path: []string{"key", "conditionalValues"}, InKey: true}, ContextAt([]byte(source+`"b": {}}}}`), len(source)))
+}
@@ -0,0 +1,276 @@
+// Package lint checks a config against the lint rules enabled in the tool config. Unlike the validators, which
+// reject configs the Remote Config API would refuse, lint rules enforce the conventions of a project.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/condition"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+// Rule names, as used in the tool config.
+const (
+	DescriptionRequired  = "description-required"
+	KeyNaming            = "key-naming"
+	MaxValueSize         = "max-value-size"
+	MaxConditions        = "max-conditions"
+	BannedCharacters     = "banned-characters"
+	ExpiredDateCondition = "expired-date-condition"
+	JsonDepth            = "json-depth"
+)
+
+// Finding is a problem reported by a rule, for the parameter or condition Key defined in File.
+type Finding struct {
+	Rule     string
+	Severity string
+	File     string
+	Key      string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", f.File, f.Key, f.Message, f.Rule)
+}
+
+// Sources maps parameter keys and condition names to the file they are defined in.
+type Sources struct {
+	Parameters map[string]string
+	Conditions map[string]string
+}
+
+type rule struct {
+	check func(l *Linter, r *run)
+	// requires names the option the rule cannot work without.
+	requires string
+}
+
+var rules = map[string]rule{
+	DescriptionRequired:  {check: checkDescription},
+	KeyNaming:            {check: checkKeyNaming, requires: "pattern"},
+	MaxValueSize:         {check: checkValueSize, requires: "max"},
+	MaxConditions:        {check: checkConditionCount, requires: "max"},
+	BannedCharacters:     {check: checkBannedCharacters, requires: "characters"},
+	ExpiredDateCondition: {check: checkExpiredConditions},
+	JsonDepth:            {check: checkJsonDepth, requires: "max"},
+}
+
+// Linter runs the enabled rules.
+type Linter struct {
+	rules   map[string]config.LintRule
+	pattern *regexp.Regexp
+	now     func() time.Time
+}
+
+// New returns a Linter for the rules of cfg, checking that they exist and have the options they need.
+func New(cfg config.LintConfig) (*Linter, error) {
+	l := &Linter{rules: map[string]config.LintRule{}, now: time.Now}
+	for name, options := range cfg.Rules {
+		r, ok := rules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown lint rule %s", name)
+		}
+		if options.Severity == config.SeverityOff || options.Severity == "" {
+			continue
+		}
+		missing := (r.requires == "pattern" && options.Pattern == "") ||
+			(r.requires == "max" && options.Max == 0) ||
+			(r.requires == "characters" && options.Characters == "")
+		if missing {
+			return nil, fmt.Errorf("lint rule %s needs %s", name, r.requires)
+		}
+		if name == KeyNaming {
+			pattern, err := regexp.Compile(options.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for lint rule %s: %s", name, err.Error())
+			}
+			l.pattern = pattern
+		}
+		l.rules[name] = options
+	}
+	return l, nil
+}
+
+// Enabled tells whether any rule is enabled.
+func (l *Linter) Enabled() bool {
+	return len(l.rules) != 0
+}
+
+// run collects the findings of one rule.
+type run struct {
+	name     string
+	options  config.LintRule
+	config   *model.Config
+	sources  Sources
+	findings []Finding
+}
+
+func (r *run) parameter(key, message string) {
+	r.findings = append(r.findings, Finding{Rule: r.name, Severity: r.options.Severity, File: r.sources.Parameters[key], Key: key, Message: message})
+}
+
+func (r *run) condition(name, message string) {
+	r.findings = append(r.findings, Finding{Rule: r.name, Severity: r.options.Severity, File: r.sources.Conditions[name], Key: name, Message: message})
+}
+
+// Lint runs the enabled rules on c, returning the findings ordered by file and key.
+func (l *Linter) Lint(c *model.Config, sources Sources) []Finding {
+	findings := []Finding{}
+	for name, options := range l.rules {
+		r := &run{name: name, options: options, config: c, sources: sources}
+		rules[name].check(l, r)
+		findings = append(findings, r.findings...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+	return findings
+}
+
+// HasErrors tells whether any finding has the error severity.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == config.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the default and conditional values of a parameter, labelled for messages.
+func values(parameter model.Parameter) map[string]string {
+	result := map[string]string{}
+	if parameter.DefaultValue != nil && !parameter.DefaultValue.UseInAppDefault {
+		result["default value"] = parameter.DefaultValue.ExplicitValue
+	}
+	for name, value := range parameter.ConditionalValues {
+		if !value.UseInAppDefault {
+			result[fmt.Sprintf("value for %s", name)] = value.ExplicitValue
+		}
+	}
+	return result
+}
+
+func checkDescription(l *Linter, r *run) {
+	for key, parameter := range r.config.Parameters {
+		if strings.TrimSpace(parameter.Description) == "" {
+			r.parameter(key, "description is missing")
+		}
+	}
+}
+
+func checkKeyNaming(l *Linter, r *run) {
+	for key := range r.config.Parameters {
+		if !l.pattern.MatchString(key) {
+			r.parameter(key, fmt.Sprintf("key does not match %s", r.options.Pattern))
+		}
+	}
+}
+
+func checkValueSize(l *Linter, r *run) {
+	for key, parameter := range r.config.Parameters {
+		for label, value := range values(parameter) {
+			if len(value) > r.options.Max {
+				r.parameter(key, fmt.Sprintf("%s is %d bytes, more than %d", label, len(value), r.options.Max))
+			}
+		}
+	}
+}
+
+func checkConditionCount(l *Linter, r *run) {
+	for key, parameter := range r.config.Parameters {
+		if len(parameter.ConditionalValues) > r.options.Max {
+			r.parameter(key, fmt.Sprintf("%d conditional values, more than %d", len(parameter.ConditionalValues), r.options.Max))
+		}
+	}
+}
+
+func checkBannedCharacters(l *Linter, r *run) {
+	banned := func(s string) []string {
+		found := []string{}
+		for _, c := range r.options.Characters {
+			if strings.ContainsRune(s, c) {
+				found = append(found, fmt.Sprintf("%q", c))
+			}
+		}
+		return found
+	}
+	for key, parameter := range r.config.Parameters {
+		if found := banned(key); len(found) != 0 {
+			r.parameter(key, fmt.Sprintf("key contains %s", strings.Join(found, ", ")))
+		}
+		for label, value := range values(parameter) {
+			if found := banned(value); len(found) != 0 {
+				r.parameter(key, fmt.Sprintf("%s contains %s", label, strings.Join(found, ", ")))
+			}
+		}
+	}
+}
+
+func checkExpiredConditions(l *Linter, r *run) {
+	now := l.now()
+	for _, c := range r.config.Conditions {
+		expr, err := condition.Parse(c.Expression)
+		if err != nil {
+			continue
+		}
+		if condition.NeverMatchesAfter(expr, now) {
+			r.condition(c.Name, "the dates of the condition have passed, it can never match again")
+		}
+	}
+}
+
+func checkJsonDepth(l *Linter, r *run) {
+	for key, parameter := range r.config.Parameters {
+		if strings.ToLower(parameter.ValueType) != "json" {
+			continue
+		}
+		for label, value := range values(parameter) {
+			var decoded interface{}
+			if json.Unmarshal([]byte(value), &decoded) != nil {
+				continue
+			}
+			if depth := jsonDepth(decoded); depth > r.options.Max {
+				r.parameter(key, fmt.Sprintf("%s is nested %d levels deep, more than %d", label, depth, r.options.Max))
+			}
+		}
+	}
+}
+
+// jsonDepth counts the nesting of objects and arrays in a decoded json value; a scalar has depth 0.
+func jsonDepth(value interface{}) int {
+	deepest := 0
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, child := range typed {
+			if depth := jsonDepth(child); depth > deepest {
+				deepest = depth
+			}
+		}
+	case []interface{}:
+		for _, child := range typed {
+			if depth := jsonDepth(child); depth > deepest {
+				deepest = depth
+			}
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
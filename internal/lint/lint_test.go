@@ -0,0 +1,104 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LintTestSuite struct {
+	suite.Suite
+	config  *model.Config
+	sources Sources
+}
+
+func TestLint(t *testing.T) {
+	suite.Run(t, new(LintTestSuite))
+}
+
+func (c *LintTestSuite) SetupTest() {
+	c.config = &model.Config{
+		Conditions: []model.Condition{
+			{Name: "sale", Expression: "dateTime < dateTime('2021-08-31T00:00:00', 'Asia/Calcutta')"},
+			{Name: "ios", Expression: "device.os == 'ios'"},
+		},
+		Parameters: map[string]model.Parameter{
+			"good_key": {
+				DefaultValue: &model.ParameterValue{ExplicitValue: `{"a": 1}`},
+				Description:  "documented",
+				ValueType:    "json",
+			},
+			"BadKey": {
+				DefaultValue:      &model.ParameterValue{ExplicitValue: "zero​width"},
+				ConditionalValues: map[string]model.ParameterValue{"sale": {ExplicitValue: "x"}, "ios": {ExplicitValue: "y"}},
+				ValueType:         "string",
+			},
+			"nested": {
+				DefaultValue: &model.ParameterValue{ExplicitValue: `{"a": {"b": [1]}}`},
+				Description:  "documented",
+				ValueType:    "json",
+			},
+		},
+	}
+	c.sources = Sources{
+		Parameters: map[string]string{"good_key": "parameters/a.json", "BadKey": "parameters/b.json", "nested": "parameters/a.json"},
+		Conditions: map[string]string{"sale": "conditions/conditions.json", "ios": "conditions/conditions.json"},
+	}
+}
+
+func (c *LintTestSuite) lint(rules map[string]config.LintRule) []string {
+	linter, err := New(config.LintConfig{Rules: rules})
+	if !assert.NoError(c.T(), err) {
+		return nil
+	}
+	linter.now = func() time.Time { return time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC) }
+	messages := []string{}
+	for _, finding := range linter.Lint(c.config, c.sources) {
+		messages = append(messages, finding.Severity+" "+finding.String())
+	}
+	return messages
+}
+
+func (c *LintTestSuite) TestRulesAreOffByDefault() {
+	assert.Empty(c.T(), c.lint(nil))
+	assert.Empty(c.T(), c.lint(map[string]config.LintRule{DescriptionRequired: {Severity: config.SeverityOff}}))
+}
+
+func (c *LintTestSuite) TestRules() {
+	assert.Equal(c.T(), []string{
+		"error conditions/conditions.json: sale: the dates of the condition have passed, it can never match again [expired-date-condition]",
+		"warn parameters/a.json: nested: default value is nested 3 levels deep, more than 2 [json-depth]",
+		"error parameters/b.json: BadKey: default value contains '\\u200b' [banned-characters]",
+		"warn parameters/b.json: BadKey: description is missing [description-required]",
+		"error parameters/b.json: BadKey: key does not match ^[a-z_]+$ [key-naming]",
+		"warn parameters/b.json: BadKey: 2 conditional values, more than 1 [max-conditions]",
+	}, c.lint(map[string]config.LintRule{
+		DescriptionRequired:  {Severity: config.SeverityWarn},
+		KeyNaming:            {Severity: config.SeverityError, Pattern: "^[a-z_]+$"},
+		MaxValueSize:         {Severity: config.SeverityWarn, Max: 100},
+		MaxConditions:        {Severity: config.SeverityWarn, Max: 1},
+		BannedCharacters:     {Severity: config.SeverityError, Characters: "​"},
+		ExpiredDateCondition: {Severity: config.SeverityError},
+		JsonDepth:            {Severity: config.SeverityWarn, Max: 2},
+	}))
+}
+
+func (c *LintTestSuite) TestValueSize() {
+	assert.Equal(c.T(), []string{
+		"warn parameters/a.json: nested: default value is 17 bytes, more than 10 [max-value-size]",
+		"warn parameters/b.json: BadKey: default value is 12 bytes, more than 10 [max-value-size]",
+	}, c.lint(map[string]config.LintRule{MaxValueSize: {Severity: config.SeverityWarn, Max: 10}}))
+}
+
+func (c *LintTestSuite) TestInvalidRules() {
+	_, err := New(config.LintConfig{Rules: map[string]config.LintRule{"unknown": {Severity: config.SeverityWarn}}})
+	assert.EqualError(c.T(), err, "unknown lint rule unknown")
+	_, err = New(config.LintConfig{Rules: map[string]config.LintRule{KeyNaming: {Severity: config.SeverityWarn}}})
+	assert.EqualError(c.T(), err, "lint rule key-naming needs pattern")
+	_, err = New(config.LintConfig{Rules: map[string]config.LintRule{KeyNaming: {Severity: config.SeverityWarn, Pattern: "("}}})
+	assert.Error(c.T(), err)
+}
@@ -0,0 +1,292 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/jsonpos"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/spf13/afero"
+)
+
+const maxHoverValueLength = 200
+
+func (s *Server) diagnostics(path string, contents []byte) []Diagnostic {
+	doc := jsonpos.Parse(contents)
+	diagnostics := []Diagnostic{}
+	report := func(span jsonpos.Span, severity int, message string) {
+		diagnostics = append(diagnostics, Diagnostic{Range: spanRange(doc, span), Severity: severity, Source: "firebase-ctl", Message: message})
+	}
+	if doc.Err != nil {
+		if syntaxErr, ok := doc.Err.(*jsonpos.SyntaxError); ok {
+			report(jsonpos.Span{Start: syntaxErr.Offset, End: syntaxErr.Offset}, SeverityError, syntaxErr.Msg)
+		}
+		return diagnostics
+	}
+	switch kindOf(path) {
+	case parametersFile:
+		parameters := map[string]model.Parameter{}
+		if err := json.Unmarshal(contents, &parameters); err != nil {
+			report(jsonpos.Span{}, SeverityError, err.Error())
+			return diagnostics
+		}
+		localConfig, err := s.store.GetLocalConfig(sourceDir(path))
+		checkReferences := err == nil || len(localConfig.Conditions) != 0
+		defined := map[string]bool{}
+		for _, c := range localConfig.Conditions {
+			defined[c.Name] = true
+		}
+		var namespaces []config.Namespace
+		if toolConfig, err := config.LoadToolConfig(sourceDir(path)); err == nil {
+			namespaces = toolConfig.Namespaces
+		}
+		for _, key := range parameterKeys(parameters) {
+			node, _ := doc.Lookup(key)
+			single := map[string]model.Parameter{key: parameters[key]}
+			if parameters[key].DefaultValue == nil {
+				report(node.Key, SeverityError, fmt.Sprintf("missing defaultValue for key %s", key))
+			} else {
+				for _, err := range utils.ValidateParameters(single) {
+					report(problemSpan(doc, node, err), SeverityError, err.Error())
+				}
+			}
+			for _, err := range utils.ValidateNamespaces(single, namespaces) {
+				severity := SeverityError
+				if v, ok := err.(*utils.ValidationError); ok && v.Warning {
+					severity = SeverityWarning
+				}
+				report(node.Key, severity, err.Error())
+			}
+			if !checkReferences {
+				continue
+			}
+			for _, name := range conditionalValueNames(parameters[key].ConditionalValues) {
+				if !defined[name] {
+					conditional, _ := doc.Lookup(key, "conditionalValues", name)
+					report(conditional.Key, SeverityError, fmt.Sprintf("undefined condition %s", name))
+				}
+			}
+		}
+	case conditionsFile:
+		conditions := []model.Condition{}
+		if err := json.Unmarshal(contents, &conditions); err != nil {
+			report(jsonpos.Span{}, SeverityError, err.Error())
+			return diagnostics
+		}
+		seen := map[string]bool{}
+		for i, c := range conditions {
+			index := strconv.Itoa(i)
+			if seen[c.Name] {
+				name, _ := doc.Lookup(index, "name")
+				report(name.Value, SeverityError, fmt.Sprintf("duplicate condition %s", c.Name))
+			}
+			seen[c.Name] = true
+			expression, ok := doc.Lookup(index, "expression")
+			if !ok {
+				expression, _ = doc.Lookup(index)
+			}
+			for _, err := range utils.ValidateConditions([]model.Condition{c}, nil) {
+				severity := SeverityError
+				if v, ok := err.(*utils.ValidationError); ok && v.Warning {
+					severity = SeverityWarning
+				}
+				report(expression.Value, severity, err.Error())
+			}
+		}
+	}
+	return diagnostics
+}
+
+func (s *Server) completion(path string, position Position) []CompletionItem {
+	items := []CompletionItem{}
+	if kindOf(path) != parametersFile {
+		return items
+	}
+	contents, err := s.read(path)
+	if err != nil {
+		return items
+	}
+	offset := jsonpos.Parse(contents).Offset(position.Line, position.Character)
+	context := jsonpos.ContextAt(contents, offset)
+	if !context.InKey || len(context.Path) != 2 || context.Path[1] != "conditionalValues" {
+		return items
+	}
+	localConfig, _ := s.store.GetLocalConfig(sourceDir(path))
+	for _, c := range localConfig.Conditions {
+		items = append(items, CompletionItem{Label: c.Name, Kind: completionKindEnumMember, Detail: c.Expression})
+	}
+	return items
+}
+
+func (s *Server) hover(path string, position Position) *Hover {
+	if kindOf(path) != parametersFile {
+		return nil
+	}
+	contents, err := s.read(path)
+	if err != nil {
+		return nil
+	}
+	doc := jsonpos.Parse(contents)
+	offset := doc.Offset(position.Line, position.Character)
+	node, ok := doc.At(offset)
+	if !ok || len(node.Path) == 0 {
+		return nil
+	}
+	key := node.Path[0]
+	top, _ := doc.Lookup(key)
+	if !top.Key.Contains(offset) {
+		return nil
+	}
+	keyRange := spanRange(doc, top.Key)
+	return &Hover{Contents: markupContent{Kind: "markdown", Value: s.remoteDescription(key)}, Range: &keyRange}
+}
+
+// remoteDescription describes the remote value of a parameter and the template version it comes from.
+func (s *Server) remoteDescription(key string) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("**%s**\n\n", key))
+	template, err := s.remoteConfig()
+	if template == nil {
+		if err == nil {
+			err = fmt.Errorf("remote is not configured")
+		}
+		sb.WriteString(fmt.Sprintf("remote value unavailable: %s", err.Error()))
+		return sb.String()
+	}
+	parameter, ok := template.Parameters[key]
+	if !ok {
+		for _, group := range template.ParameterGroups {
+			if p, inGroup := group.Parameters[key]; inGroup && p != nil {
+				parameter, ok = *p, true
+			}
+		}
+	}
+	if !ok {
+		sb.WriteString("not on remote yet\n\n")
+	} else {
+		source := model.ConvertToSourceParameter(parameter)
+		sb.WriteString(fmt.Sprintf("remote default: %s\n\n", hoverValue(key, source.DefaultValue)))
+		for _, name := range conditionalValueNames(source.ConditionalValues) {
+			value := source.ConditionalValues[name]
+			sb.WriteString(fmt.Sprintf("remote `%s`: %s\n\n", name, hoverValue(key, &value)))
+		}
+	}
+	version := template.Version
+	sb.WriteString(fmt.Sprintf("template version %d, updated %s", version.VersionNumber, version.UpdateTime.Format("2006-01-02 15:04:05 MST")))
+	if version.UpdateUser != nil && version.UpdateUser.Email != "" {
+		sb.WriteString(" by " + version.UpdateUser.Email)
+	}
+	return sb.String()
+}
+
+func hoverValue(key string, value *model.ParameterValue) string {
+	switch {
+	case value == nil || value.UseInAppDefault:
+		return "in-app default"
+	case utils.IsSecretKey(key):
+		return "`*******`"
+	case utf8.RuneCountInString(value.ExplicitValue) > maxHoverValueLength:
+		return "`" + string([]rune(value.ExplicitValue)[:maxHoverValueLength]) + "…`"
+	}
+	return "`" + value.ExplicitValue + "`"
+}
+
+func (s *Server) definition(path string, position Position) []Location {
+	locations := []Location{}
+	if kindOf(path) != parametersFile {
+		return locations
+	}
+	contents, err := s.read(path)
+	if err != nil {
+		return locations
+	}
+	doc := jsonpos.Parse(contents)
+	offset := doc.Offset(position.Line, position.Character)
+	node, ok := doc.At(offset)
+	if !ok || len(node.Path) != 3 || node.Path[1] != "conditionalValues" || !node.Key.Contains(offset) {
+		return locations
+	}
+	if location, ok := s.findCondition(sourceDir(path), node.Path[2]); ok {
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+// findCondition locates the definition of a condition in the conditions directory of dir.
+func (s *Server) findCondition(dir, name string) (Location, bool) {
+	conditionsDir := filepath.Join(dir, config.ConditionsDir)
+	infos, err := afero.ReadDir(s.fs, conditionsDir)
+	if err != nil {
+		return Location{}, false
+	}
+	for _, info := range infos {
+		path := filepath.Join(conditionsDir, info.Name())
+		if info.IsDir() || kindOf(path) != conditionsFile {
+			continue
+		}
+		contents, err := s.read(path)
+		if err != nil {
+			continue
+		}
+		doc := jsonpos.Parse(contents)
+		for _, node := range doc.Nodes {
+			if len(node.Path) != 2 || node.Path[1] != "name" {
+				continue
+			}
+			if value, ok := doc.String(node.Value); ok && value == name {
+				element, _ := doc.Lookup(node.Path[0])
+				return Location{URI: pathToURI(path), Range: spanRange(doc, element.Value)}, true
+			}
+		}
+	}
+	return Location{}, false
+}
+
+// problemSpan narrows the span of the parameter at node to the value, or the character in a string value, that a
+// validation error is about.
+func problemSpan(doc *jsonpos.Document, node jsonpos.Node, err error) jsonpos.Span {
+	v, ok := err.(*utils.ValidationError)
+	if !ok || len(v.Path) == 0 {
+		return node.Key
+	}
+	value, ok := doc.Lookup(append(append([]string{}, node.Path...), v.Path...)...)
+	if !ok {
+		return node.Key
+	}
+	if _, isString := doc.String(value.Value); isString && v.Offset >= 0 {
+		offset := doc.StringOffset(value.Value, v.Offset)
+		return jsonpos.Span{Start: offset, End: offset + 1}
+	}
+	return value.Value
+}
+
+func spanRange(doc *jsonpos.Document, span jsonpos.Span) Range {
+	startLine, startCharacter := doc.Position(span.Start)
+	endLine, endCharacter := doc.Position(span.End)
+	return Range{Start: Position{Line: startLine, Character: startCharacter}, End: Position{Line: endLine, Character: endCharacter}}
+}
+
+func parameterKeys(parameters map[string]model.Parameter) []string {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func conditionalValueNames(values map[string]model.ParameterValue) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,230 @@
+// Package lsp implements a language server for firebase-ctl source directories: diagnostics from the validators,
+// completion of condition names in conditionalValues, hover with the remote value of a parameter, and go to
+// definition from a conditional value to its condition.
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"github.com/spf13/afero"
+)
+
+// Server is a language server over a single connection. Open documents are layered over the files on disk, so
+// diagnostics reflect unsaved edits.
+type Server struct {
+	conn    *conn
+	overlay afero.Fs
+	fs      afero.Fs
+	store   *firebase.ClientStore
+	// documents holds the open documents by path.
+	documents map[string][]byte
+
+	remote         func() (*remoteconfig.RemoteConfig, error)
+	remoteLoaded   bool
+	remoteTemplate *remoteconfig.RemoteConfig
+	remoteErr      error
+}
+
+// NewServer returns a Server reading sources from base. remote returns the template hover information comes from;
+// it is called at most once, and may be nil when no project is configured.
+func NewServer(base afero.Fs, remote func() (*remoteconfig.RemoteConfig, error)) *Server {
+	overlay := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(base, overlay)
+	return &Server{
+		overlay:   overlay,
+		fs:        fs,
+		store:     firebase.NewLocalClientStoreWithFs(fs),
+		documents: map[string][]byte{},
+		remote:    remote,
+	}
+}
+
+// Serve handles the messages read from r until the client exits or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		req, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, rpcErr := s.handle(req)
+		if req.ID == nil {
+			continue
+		}
+		if rpcErr != nil {
+			err = s.conn.write(errorResponse{JsonRPC: "2.0", ID: req.ID, Error: rpcErr})
+		} else {
+			err = s.conn.write(response{JsonRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		result := initializeResult{}
+		result.Capabilities.TextDocumentSync = textDocumentSyncFull
+		result.Capabilities.CompletionProvider.TriggerCharacters = []string{`"`}
+		result.Capabilities.HoverProvider = true
+		result.Capabilities.DefinitionProvider = true
+		result.ServerInfo.Name = "firebase-ctl"
+		return result, nil
+	case "initialized", "$/cancelRequest", "textDocument/didSave":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.open(uriToPath(params.TextDocument.URI), []byte(params.TextDocument.Text))
+		return nil, nil
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(params.ContentChanges) != 0 {
+			s.open(uriToPath(params.TextDocument.URI), []byte(params.ContentChanges[len(params.ContentChanges)-1].Text))
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		params := didCloseParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.close(params.TextDocument.URI)
+		return nil, nil
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.completion(uriToPath(params.TextDocument.URI), params.Position), nil
+	case "textDocument/hover":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.hover(uriToPath(params.TextDocument.URI), params.Position), nil
+	case "textDocument/definition":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return s.definition(uriToPath(params.TextDocument.URI), params.Position), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func (s *Server) open(path string, contents []byte) {
+	if path == "" {
+		return
+	}
+	s.documents[path] = contents
+	s.overlay.MkdirAll(filepath.Dir(path), 0755)
+	afero.WriteFile(s.overlay, path, contents, 0644)
+	s.publishDiagnostics()
+}
+
+func (s *Server) close(uri string) {
+	path := uriToPath(uri)
+	delete(s.documents, path)
+	s.overlay.Remove(path)
+	s.conn.write(notification{JsonRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: publishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}}})
+	s.publishDiagnostics()
+}
+
+// publishDiagnostics validates every open document again, as a change to one file can fix or break references in
+// the others.
+func (s *Server) publishDiagnostics() {
+	paths := make([]string, 0, len(s.documents))
+	for path := range s.documents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		s.conn.write(notification{
+			JsonRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  publishDiagnosticsParams{URI: pathToURI(path), Diagnostics: s.diagnostics(path, s.documents[path])},
+		})
+	}
+}
+
+// read returns the contents of path, preferring the open document.
+func (s *Server) read(path string) ([]byte, error) {
+	if contents, ok := s.documents[path]; ok {
+		return contents, nil
+	}
+	return afero.ReadFile(s.fs, path)
+}
+
+func (s *Server) remoteConfig() (*remoteconfig.RemoteConfig, error) {
+	if !s.remoteLoaded && s.remote != nil {
+		s.remoteTemplate, s.remoteErr = s.remote()
+		s.remoteLoaded = true
+	}
+	return s.remoteTemplate, s.remoteErr
+}
+
+type fileKind int
+
+const (
+	otherFile fileKind = iota
+	parametersFile
+	conditionsFile
+)
+
+func kindOf(path string) fileKind {
+	if filepath.Ext(path) != ".json" {
+		return otherFile
+	}
+	switch filepath.Base(filepath.Dir(path)) {
+	case config.ParametersDir, config.SecretParametersDir:
+		return parametersFile
+	case config.ConditionsDir:
+		if filepath.Base(path) != config.ConditionsOrderFile {
+			return conditionsFile
+		}
+	}
+	return otherFile
+}
+
+// sourceDir returns the input directory a source file belongs to.
+func sourceDir(path string) string {
+	return filepath.Dir(filepath.Dir(path))
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,204 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	fs     afero.Fs
+	server *Server
+	input  bytes.Buffer
+	nextID int
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+const conditionsSource = `[
+  {"name": "ios", "expression": "device.os == 'ios'"},
+  {"name": "android", "expression": "device.os == 'android'"}
+]`
+
+const parametersSource = `{
+  "banner": {
+    "defaultValue": {"value": "default"},
+    "conditionalValues": {"ios": {"value": "ios"}},
+    "valueType": "string"
+  }
+}`
+
+func (c *ServerTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	afero.WriteFile(c.fs, "/src/conditions/conditions.json", []byte(conditionsSource), 0644)
+	afero.WriteFile(c.fs, "/src/parameters/parameters.json", []byte(parametersSource), 0644)
+	c.server = NewServer(c.fs, func() (*remoteconfig.RemoteConfig, error) {
+		return &remoteconfig.RemoteConfig{
+			Parameters: map[string]remoteconfig.Parameter{
+				"banner": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "remote"}},
+			},
+			Version: remoteconfig.Version{
+				VersionNumber: 7,
+				UpdateTime:    time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC),
+				UpdateUser:    &remoteconfig.User{Email: "jane@example.com"},
+			},
+		}, nil
+	})
+	c.input.Reset()
+	c.nextID = 0
+}
+
+func (c *ServerTestSuite) send(method string, params interface{}, isRequest bool) {
+	message := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if isRequest {
+		c.nextID++
+		message["id"] = c.nextID
+	}
+	body, _ := json.Marshal(message)
+	fmt.Fprintf(&c.input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (c *ServerTestSuite) open(path, text string) {
+	c.send("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": "file://" + path, "text": text}}, false)
+}
+
+func (c *ServerTestSuite) at(method, path string, line, character int) {
+	c.send(method, map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file://" + path},
+		"position":     map[string]interface{}{"line": line, "character": character},
+	}, true)
+}
+
+// run serves the queued messages and returns the responses by id and the notifications in order.
+func (c *ServerTestSuite) run() (map[int]json.RawMessage, []json.RawMessage) {
+	output := bytes.Buffer{}
+	assert.NoError(c.T(), c.server.Serve(&c.input, &output))
+	responses := map[int]json.RawMessage{}
+	notifications := []json.RawMessage{}
+	reader := bufio.NewReader(&output)
+	for {
+		headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(c.T(), err)
+		length, _ := strconv.Atoi(headers.Get("Content-Length"))
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		message := struct {
+			ID     *int            `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Params json.RawMessage `json:"params"`
+		}{}
+		assert.NoError(c.T(), json.Unmarshal(body, &message))
+		if message.ID != nil {
+			responses[*message.ID] = message.Result
+		} else {
+			notifications = append(notifications, message.Params)
+		}
+	}
+	return responses, notifications
+}
+
+func (c *ServerTestSuite) TestInitialize() {
+	c.send("initialize", map[string]interface{}{}, true)
+	responses, _ := c.run()
+	result := initializeResult{}
+	assert.NoError(c.T(), json.Unmarshal(responses[1], &result))
+	assert.True(c.T(), result.Capabilities.HoverProvider)
+	assert.True(c.T(), result.Capabilities.DefinitionProvider)
+}
+
+func (c *ServerTestSuite) TestDiagnosticsFollowUnsavedEdits() {
+	c.open("/src/parameters/parameters.json", `{
+  "banner": {
+    "defaultValue": {"value": "{"},
+    "conditionalValues": {"web": {"value": "{}"}},
+    "valueType": "json"
+  }
+}`)
+	_, notifications := c.run()
+	params := publishDiagnosticsParams{}
+	assert.NoError(c.T(), json.Unmarshal(notifications[0], &params))
+	assert.Equal(c.T(), "file:///src/parameters/parameters.json", params.URI)
+	assert.Len(c.T(), params.Diagnostics, 2)
+	assert.Equal(c.T(), "invalid json for key banner. error:invalid json in default value. unexpected end of JSON input", params.Diagnostics[0].Message)
+	// the problem is placed inside the escaped value
+	assert.Equal(c.T(), Range{Start: Position{Line: 2, Character: 31}, End: Position{Line: 2, Character: 32}}, params.Diagnostics[0].Range)
+	assert.Equal(c.T(), "undefined condition web", params.Diagnostics[1].Message)
+	assert.Equal(c.T(), Position{Line: 3, Character: 26}, params.Diagnostics[1].Range.Start)
+
+	// defining the condition in an unsaved conditions file fixes the reference
+	c.open("/src/conditions/conditions.json", `[{"name": "web", "expression": "device.os == "}]`)
+	_, notifications = c.run()
+	conditions, parameters := publishDiagnosticsParams{}, publishDiagnosticsParams{}
+	json.Unmarshal(notifications[0], &conditions)
+	json.Unmarshal(notifications[1], &parameters)
+	assert.Len(c.T(), conditions.Diagnostics, 1)
+	assert.Contains(c.T(), conditions.Diagnostics[0].Message, "invalid expression for condition web")
+	assert.Len(c.T(), parameters.Diagnostics, 1)
+}
+
+func (c *ServerTestSuite) TestSyntaxErrorDiagnostic() {
+	c.open("/src/parameters/parameters.json", "{\n  \"banner\": tru\n}")
+	_, notifications := c.run()
+	params := publishDiagnosticsParams{}
+	json.Unmarshal(notifications[0], &params)
+	assert.Len(c.T(), params.Diagnostics, 1)
+	assert.Equal(c.T(), Position{Line: 1, Character: 12}, params.Diagnostics[0].Range.Start)
+}
+
+func (c *ServerTestSuite) TestCompletionOfConditionNames() {
+	c.open("/src/parameters/parameters.json", "{\"banner\": {\"conditionalValues\": {\"\n")
+	c.at("textDocument/completion", "/src/parameters/parameters.json", 0, 36)
+	responses, _ := c.run()
+	items := []CompletionItem{}
+	assert.NoError(c.T(), json.Unmarshal(responses[1], &items))
+	assert.Equal(c.T(), []CompletionItem{
+		{Label: "ios", Kind: completionKindEnumMember, Detail: "device.os == 'ios'"},
+		{Label: "android", Kind: completionKindEnumMember, Detail: "device.os == 'android'"},
+	}, items)
+}
+
+func (c *ServerTestSuite) TestHoverShowsRemoteValue() {
+	c.at("textDocument/hover", "/src/parameters/parameters.json", 1, 4)
+	responses, _ := c.run()
+	hover := Hover{}
+	assert.NoError(c.T(), json.Unmarshal(responses[1], &hover))
+	assert.Equal(c.T(), "**banner**\n\nremote default: `remote`\n\ntemplate version 7, updated 2021-09-01 10:00:00 UTC by jane@example.com", hover.Contents.Value)
+}
+
+func (c *ServerTestSuite) TestHoverValueIsCutByCharacter() {
+	value := strings.Repeat("é", maxHoverValueLength+1)
+	hover := hoverValue("long", &model.ParameterValue{ExplicitValue: value})
+	assert.True(c.T(), utf8.ValidString(hover))
+	assert.Equal(c.T(), "`"+strings.Repeat("é", maxHoverValueLength)+"…`", hover)
+}
+
+func (c *ServerTestSuite) TestDefinitionOfCondition() {
+	c.at("textDocument/definition", "/src/parameters/parameters.json", 3, 27)
+	responses, _ := c.run()
+	locations := []Location{}
+	assert.NoError(c.T(), json.Unmarshal(responses[1], &locations))
+	assert.Equal(c.T(), []Location{{
+		URI:   "file:///src/conditions/conditions.json",
+		Range: Range{Start: Position{Line: 1, Character: 2}, End: Position{Line: 1, Character: 53}},
+	}}, locations)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"strings"
 	"time"
 )
 
@@ -45,6 +46,35 @@ type Parameter struct {
 	DefaultValue      *ParameterValue           `json:"defaultValue"`
 	Description       string                    `json:"description"`
 	ValueType         string                    `json:"valueType"`
+	// Protected parameters cannot be deleted by an apply. It is published as ProtectedMarker at the end of the
+	// description, so that the protection outlives the definition of the parameter.
+	Protected bool `json:"protected,omitempty"`
+}
+
+// ProtectedMarker ends the remote description of protected parameters, after a space unless the description is
+// empty. It is reserved: a description ending with it is read back as protected, without it.
+const ProtectedMarker = "[firebase-ctl:protected]"
+
+func publishedDescription(description string, protected bool) string {
+	if !protected {
+		return description
+	}
+	if description == "" {
+		return ProtectedMarker
+	}
+	return description + " " + ProtectedMarker
+}
+
+// IsProtected tells whether a remote parameter is protected.
+func IsProtected(parameter remoteconfig.Parameter) bool {
+	return parameter.Description == ProtectedMarker || strings.HasSuffix(parameter.Description, " "+ProtectedMarker)
+}
+
+func sourceDescription(parameter remoteconfig.Parameter) string {
+	if !IsProtected(parameter) {
+		return parameter.Description
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(parameter.Description, ProtectedMarker), " ")
 }
 
 // ParameterValue .
@@ -120,7 +150,7 @@ func convertSourceParamsToRemote(p map[string]Parameter) map[string]remoteconfig
 				ExplicitValue:   p[parameterKey].DefaultValue.ExplicitValue,
 				UseInAppDefault: p[parameterKey].DefaultValue.UseInAppDefault,
 			},
-			Description: p[parameterKey].Description,
+			Description: publishedDescription(p[parameterKey].Description, p[parameterKey].Protected),
 		}
 	}
 	return rcParams
@@ -144,24 +174,30 @@ func convertRemoteParamsToSource(p map[string]remoteconfig.Parameter) map[string
 	rcParams := map[string]Parameter{}
 
 	for oarameterKey, parameterValue := range p {
-		cv := map[string]ParameterValue{}
-		for conditionalValueKey, conditionalValueValue := range parameterValue.ConditionalValues {
-			cv[conditionalValueKey] = ParameterValue{
-				ExplicitValue:   conditionalValueValue.ExplicitValue,
-				UseInAppDefault: conditionalValueValue.UseInAppDefault,
-			}
-		}
-		if len(cv) == 0 {
-			cv = nil
-		}
-		rcParams[oarameterKey] = Parameter{
-			ConditionalValues: cv,
-			DefaultValue: &ParameterValue{
-				ExplicitValue:   p[oarameterKey].DefaultValue.ExplicitValue,
-				UseInAppDefault: p[oarameterKey].DefaultValue.UseInAppDefault,
-			},
-			Description: p[oarameterKey].Description,
-		}
+		rcParams[oarameterKey] = ConvertToSourceParameter(parameterValue)
 	}
 	return rcParams
 }
+
+// ConvertToSourceParameter converts a single remote parameter to its source representation.
+func ConvertToSourceParameter(parameterValue remoteconfig.Parameter) Parameter {
+	cv := map[string]ParameterValue{}
+	for conditionalValueKey, conditionalValueValue := range parameterValue.ConditionalValues {
+		cv[conditionalValueKey] = ParameterValue{
+			ExplicitValue:   conditionalValueValue.ExplicitValue,
+			UseInAppDefault: conditionalValueValue.UseInAppDefault,
+		}
+	}
+	if len(cv) == 0 {
+		cv = nil
+	}
+	return Parameter{
+		ConditionalValues: cv,
+		DefaultValue: &ParameterValue{
+			ExplicitValue:   parameterValue.DefaultValue.ExplicitValue,
+			UseInAppDefault: parameterValue.DefaultValue.UseInAppDefault,
+		},
+		Description: sourceDescription(parameterValue),
+		Protected:   IsProtected(parameterValue),
+	}
+}
@@ -0,0 +1,227 @@
+// Package notify tells chat tools and other webhooks about published template versions.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// timeout bounds each webhook call, so that a slow chat tool does not hold up the apply.
+const timeout = 10 * time.Second
+
+// slackEscaper escapes the characters Slack gives a meaning in message text.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Event describes an apply. Values of secret parameters are masked.
+type Event struct {
+	// Directory is the source directory that was applied.
+	Directory       string   `json:"directory"`
+	Version         int64    `json:"version"`
+	PreviousVersion int64    `json:"previousVersion"`
+	Description     string   `json:"description"`
+	User            string   `json:"user"`
+	Commit          string   `json:"commit,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	Summary         string   `json:"summary"`
+	Parameters      []Change `json:"parameters"`
+	Conditions      []Change `json:"conditions"`
+}
+
+// Change is a changed parameter or condition. Before and After are the default values of parameters and the
+// expressions of conditions, empty for added and deleted ones respectively.
+type Change struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// NewEvent returns the event of an apply from dir that published version, replacing previousVersion.
+func NewEvent(dir string, version remoteconfig.Version, previousVersion int64, changes utils.ChangeSet, git *utils.GitInfo, user string) Event {
+	event := Event{
+		Directory:       dir,
+		Version:         version.VersionNumber,
+		PreviousVersion: previousVersion,
+		Description:     version.Description,
+		User:            user,
+		Summary:         changes.Summary(),
+		Parameters:      []Change{},
+		Conditions:      []Change{},
+	}
+	if git != nil {
+		event.Commit, event.Branch = git.Commit, git.Branch
+	}
+	for _, change := range changes.Parameters {
+		event.Parameters = append(event.Parameters, Change{
+			Name:   change.Key,
+			Type:   string(change.Type),
+			Before: parameterValue(change.Key, change.Before),
+			After:  parameterValue(change.Key, change.After),
+		})
+	}
+	for _, change := range changes.Conditions {
+		c := Change{Name: change.Name, Type: string(change.Type)}
+		if change.Before != nil {
+			c.Before = change.Before.Expression
+		}
+		if change.After != nil {
+			c.After = change.After.Expression
+		}
+		event.Conditions = append(event.Conditions, c)
+	}
+	return event
+}
+
+// escaped returns a copy of e with every text passed through escape.
+func (e Event) escaped(escape func(string) string) Event {
+	escapeChanges := func(changes []Change) []Change {
+		escaped := []Change{}
+		for _, c := range changes {
+			escaped = append(escaped, Change{Name: escape(c.Name), Type: c.Type, Before: escape(c.Before), After: escape(c.After)})
+		}
+		return escaped
+	}
+	e.Directory, e.Description, e.User = escape(e.Directory), escape(e.Description), escape(e.User)
+	e.Commit, e.Branch, e.Summary = escape(e.Commit), escape(e.Branch), escape(e.Summary)
+	e.Parameters, e.Conditions = escapeChanges(e.Parameters), escapeChanges(e.Conditions)
+	return e
+}
+
+func parameterValue(key string, parameter *remoteconfig.Parameter) string {
+	if parameter == nil || parameter.DefaultValue == nil {
+		return ""
+	}
+	if utils.IsSecretKey(key) {
+		return utils.MaskedValue
+	}
+	if parameter.DefaultValue.UseInAppDefault {
+		return "(in-app default)"
+	}
+	return parameter.DefaultValue.ExplicitValue
+}
+
+const defaultSlackTemplate = "Remote Config `{{.Directory}}` published as version {{.Version}}" +
+	"{{if .PreviousVersion}} (was {{.PreviousVersion}}){{end}} by {{.User}}" +
+	"{{if .Commit}} from commit {{.Commit}}{{end}}: {{.Summary}}\n" +
+	"{{range .Parameters}}• {{.Type}} parameter `{{.Name}}`{{if .After}}: {{.After}}{{end}}\n{{end}}" +
+	"{{range .Conditions}}• {{.Type}} condition `{{.Name}}`{{if .After}}: {{.After}}{{end}}\n{{end}}"
+
+var funcs = template.FuncMap{
+	// json renders a value as JSON, for values embedded in the templates of generic webhooks.
+	"json": func(v interface{}) (string, error) {
+		contents, err := json.Marshal(v)
+		return string(contents), err
+	},
+}
+
+type hook struct {
+	url      string
+	format   string
+	template *template.Template
+}
+
+// Notifier calls the webhooks of a tool config.
+type Notifier struct {
+	hooks      []hook
+	httpClient *http.Client
+}
+
+// New returns a Notifier for notifications, checking that their templates parse.
+func New(notifications []config.Notification) (*Notifier, error) {
+	n := &Notifier{httpClient: &http.Client{Timeout: timeout}}
+	for i, notification := range notifications {
+		h := hook{url: notification.URL, format: notification.Format}
+		if h.format == "" {
+			h.format = config.NotificationSlack
+		}
+		text := notification.Template
+		if text == "" && h.format == config.NotificationSlack {
+			text = defaultSlackTemplate
+		}
+		if text != "" {
+			parsed, err := template.New(fmt.Sprintf("notifications[%d]", i)).Funcs(funcs).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template: %s", err.Error())
+			}
+			h.template = parsed
+		}
+		n.hooks = append(n.hooks, h)
+	}
+	return n, nil
+}
+
+// Notify calls every webhook with event and returns the errors of the calls that failed. The calls are not tied to the
+// context of the apply, since the template is already published when they are made, and are bounded by timeout.
+func (n *Notifier) Notify(event Event) []error {
+	errs := []error{}
+	for _, h := range n.hooks {
+		if err := n.call(h, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (n *Notifier) call(h hook, event Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	target := os.ExpandEnv(h.url)
+	// the url itself may be secret, so errors name the configured url and not its expansion
+	if target == "" {
+		return fmt.Errorf("notification %s: url is empty", h.url)
+	}
+	body, err := h.body(event)
+	if err != nil {
+		return fmt.Errorf("notification %s: %s", h.url, err.Error())
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notification %s: invalid url", h.url)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := n.httpClient.Do(request)
+	if urlErr, ok := err.(*url.Error); ok {
+		return fmt.Errorf("notification %s: %s", h.url, urlErr.Err.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("notification %s: %s", h.url, err.Error())
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		contents, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("notification %s: webhook returned %d: %s", h.url, response.StatusCode, strings.TrimSpace(string(contents)))
+	}
+	return nil
+}
+
+// body renders the payload of the webhook for event. The values of the event are escaped for Slack, so that a
+// parameter value such as <!channel> is not taken as markup.
+func (h hook) body(event Event) ([]byte, error) {
+	if h.template == nil {
+		return json.Marshal(event)
+	}
+	if h.format == config.NotificationSlack {
+		event = event.escaped(slackEscaper.Replace)
+	}
+	rendered := &bytes.Buffer{}
+	if err := h.template.Execute(rendered, event); err != nil {
+		return nil, err
+	}
+	if h.format == config.NotificationSlack {
+		return json.Marshal(map[string]string{"text": rendered.String()})
+	}
+	return rendered.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotifyTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	bodies map[string]string
+	event  Event
+}
+
+func TestNotify(t *testing.T) {
+	suite.Run(t, new(NotifyTestSuite))
+}
+
+func (c *NotifyTestSuite) SetupTest() {
+	c.bodies = map[string]string{}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid_payload\n"))
+			return
+		}
+		contents, _ := ioutil.ReadAll(r.Body)
+		c.bodies[r.URL.Path] = string(contents)
+	}))
+	changes := utils.ComputeChanges(remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_token": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "hunter2"}},
+			"theme":     {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "dark"}},
+		},
+	}, remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_token": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "hunter1"}},
+		},
+	})
+	git := &utils.GitInfo{Commit: "0123456789abcdef0123456789abcdef01234567", Branch: "main"}
+	c.event = NewEvent("config/production", remoteconfig.Version{VersionNumber: 8, Description: "raise limits"}, 7, changes, git, "Jane Doe")
+}
+
+func (c *NotifyTestSuite) TearDownTest() {
+	c.server.Close()
+}
+
+func (c *NotifyTestSuite) TestEventMasksSecrets() {
+	assert.Equal(c.T(), "2 added, 1 updated", c.event.Summary)
+	assert.Equal(c.T(), []Change{
+		{Name: "SEC_token", Type: "updated", Before: utils.MaskedValue, After: utils.MaskedValue},
+		{Name: "theme", Type: "added", After: "dark"},
+	}, c.event.Parameters)
+	assert.Equal(c.T(), []Change{{Name: "ios", Type: "added", After: "device.os == 'ios'"}}, c.event.Conditions)
+}
+
+func (c *NotifyTestSuite) TestSlackAndGenericPayloads() {
+	os.Setenv("NOTIFY_TEST_HOOK", c.server.URL+"/slack")
+	defer os.Unsetenv("NOTIFY_TEST_HOOK")
+	notifier, err := New([]config.Notification{
+		{URL: "$NOTIFY_TEST_HOOK"},
+		{URL: c.server.URL + "/generic", Format: config.NotificationGeneric},
+		{URL: c.server.URL + "/custom", Format: config.NotificationGeneric, Template: `{"v": {{.Version}}, "who": {{json .User}}}`},
+	})
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), notifier.Notify(c.event))
+
+	slack := map[string]string{}
+	assert.NoError(c.T(), json.Unmarshal([]byte(c.bodies["/slack"]), &slack))
+	assert.Equal(c.T(), "Remote Config `config/production` published as version 8 (was 7) by Jane Doe"+
+		" from commit 0123456789abcdef0123456789abcdef01234567: 2 added, 1 updated\n"+
+		"• updated parameter `SEC_token`: *******\n"+
+		"• added parameter `theme`: dark\n"+
+		"• added condition `ios`: device.os == 'ios'\n", slack["text"])
+
+	generic := Event{}
+	assert.NoError(c.T(), json.Unmarshal([]byte(c.bodies["/generic"]), &generic))
+	assert.Equal(c.T(), c.event, generic)
+	assert.NotContains(c.T(), c.bodies["/generic"], "hunter")
+
+	assert.JSONEq(c.T(), `{"v": 8, "who": "Jane Doe"}`, c.bodies["/custom"])
+}
+
+func (c *NotifyTestSuite) TestSlackTextIsEscaped() {
+	notifier, err := New([]config.Notification{{URL: c.server.URL + "/slack"}})
+	assert.NoError(c.T(), err)
+	c.event.Parameters = []Change{{Name: "banner", Type: "updated", After: "<!channel> R&D <b>"}}
+	c.event.Conditions = nil
+	assert.Empty(c.T(), notifier.Notify(c.event))
+
+	slack := map[string]string{}
+	assert.NoError(c.T(), json.Unmarshal([]byte(c.bodies["/slack"]), &slack))
+	assert.Contains(c.T(), slack["text"], "• updated parameter `banner`: &lt;!channel&gt; R&amp;D &lt;b&gt;\n")
+}
+
+func (c *NotifyTestSuite) TestFailuresAreCollected() {
+	notifier, err := New([]config.Notification{
+		{URL: c.server.URL + "/broken"},
+		{URL: "$NOTIFY_TEST_UNSET"},
+		{URL: c.server.URL + "/ok"},
+	})
+	assert.NoError(c.T(), err)
+	errs := notifier.Notify(c.event)
+	assert.Len(c.T(), errs, 2)
+	assert.EqualError(c.T(), errs[0], "notification "+c.server.URL+"/broken: webhook returned 400: invalid_payload")
+	assert.EqualError(c.T(), errs[1], "notification $NOTIFY_TEST_UNSET: url is empty")
+	assert.Contains(c.T(), c.bodies, "/ok")
+
+	_, err = New([]config.Notification{{URL: "x", Template: "{{.Version"}})
+	assert.Error(c.T(), err)
+}
@@ -0,0 +1,313 @@
+// Package report collects the problems found in a source directory and renders them for people and CI systems.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/github"
+)
+
+// Output formats.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+	// FormatGitHub prints GitHub Actions workflow commands, which annotate the files of the pull request.
+	FormatGitHub = "github"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatText, FormatJSON, FormatJUnit, FormatSARIF, FormatGitHub}
+
+// Problem is one problem found in the sources. Severity is config.SeverityError or config.SeverityWarn.
+type Problem struct {
+	Severity string `json:"severity"`
+	// File is the path of the file the problem is in, empty when it is not about a single file.
+	File string `json:"file,omitempty"`
+	// Line and Column locate the problem in File, starting at 1. Columns are counted in UTF-16 code units. They are
+	// 0 when the problem is not at a position.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+	// Key is the parameter key or the condition name the problem is about, if any.
+	Key string `json:"key,omitempty"`
+	// Rule identifies the check that found the problem.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	parts := []string{p.Severity}
+	if p.File != "" && p.Line != 0 {
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column))
+	} else if p.File != "" {
+		parts = append(parts, p.File)
+	}
+	if p.Key != "" {
+		parts = append(parts, p.Key)
+	}
+	return fmt.Sprintf("%s: %s [%s]", strings.Join(parts, ": "), p.Message, p.Rule)
+}
+
+// Report is every problem found in a source directory.
+type Report struct {
+	Problems []Problem
+}
+
+// Add records a problem.
+func (r *Report) Add(p Problem) {
+	r.Problems = append(r.Problems, p)
+}
+
+// Sort orders the problems by file, position, key and message.
+func (r *Report) Sort() {
+	sort.SliceStable(r.Problems, func(i, j int) bool {
+		a, b := r.Problems[i], r.Problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Message < b.Message
+	})
+}
+
+// Count returns the number of problems of the given severity.
+func (r *Report) Count(severity string) int {
+	count := 0
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors tells whether any problem has error severity.
+func (r *Report) HasErrors() bool {
+	return r.Count(config.SeverityError) != 0
+}
+
+// Write renders the report to w in the given format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText, "":
+		return r.writeText(w)
+	case FormatJSON:
+		return r.writeJSON(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	case FormatSARIF:
+		return r.writeSARIF(w)
+	case FormatGitHub:
+		return r.writeGitHub(w)
+	default:
+		return fmt.Errorf("unknown report format %s, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	for _, p := range r.Problems {
+		if _, err := fmt.Fprintln(w, p.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	problems := r.Problems
+	if problems == nil {
+		problems = []Problem{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Errors   int       `json:"errors"`
+		Warnings int       `json:"warnings"`
+		Problems []Problem `json:"problems"`
+	}{r.Count(config.SeverityError), r.Count(config.SeverityWarn), problems})
+}
+
+func (r *Report) writeGitHub(w io.Writer) error {
+	for _, p := range r.Problems {
+		level := "warning"
+		if p.Severity == config.SeverityError {
+			level = "error"
+		}
+		message := p.Message
+		if p.Key != "" {
+			message = p.Key + ": " + message
+		}
+		annotation := github.Annotation{Level: level, File: filepath.ToSlash(p.File), Line: p.Line, Column: p.Column, Title: p.Rule, Message: message}
+		if _, err := fmt.Fprintln(w, annotation.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	TestCases []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit renders every problem as a test case named after its key, failed for errors. Warnings pass, with the
+// problem in their output. A report without problems is a single passing test case.
+func (r *Report) writeJUnit(w io.Writer) error {
+	suite := junitSuite{Name: "firebase-ctl validate"}
+	for _, p := range r.Problems {
+		name := p.Key
+		if name == "" {
+			name = p.Rule
+		}
+		testCase := junitCase{Name: name, ClassName: p.File}
+		if p.Severity == config.SeverityError {
+			testCase.Failure = &junitFailure{Message: p.Message, Type: p.Rule, Text: p.String()}
+			suite.Failures++
+		} else {
+			testCase.SystemOut = p.String()
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = append(suite.TestCases, junitCase{Name: "validate", ClassName: "firebase-ctl"})
+	}
+	suite.Tests = len(suite.TestCases)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// writeSARIF renders the report as a SARIF 2.1.0 log, which code scanning tools show as annotations.
+func (r *Report) writeSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "firebase-ctl",
+			InformationURI: "https://github.com/rapido-labs/firebase-ctl",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	seen := map[string]bool{}
+	for _, p := range r.Problems {
+		if !seen[p.Rule] {
+			seen[p.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: p.Rule})
+		}
+		level := "warning"
+		if p.Severity == config.SeverityError {
+			level = "error"
+		}
+		result := sarifResult{RuleID: p.Rule, Level: level, Message: sarifMessage{Text: p.Message}}
+		if p.File != "" {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(p.File)}}
+			if p.Line != 0 {
+				location.Region = &sarifRegion{StartLine: p.Line, StartColumn: p.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		run.Results = append(run.Results, result)
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
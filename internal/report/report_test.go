@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportTestSuite struct {
+	suite.Suite
+	report *Report
+}
+
+func TestReport(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+func (c *ReportTestSuite) SetupTest() {
+	c.report = &Report{}
+	c.report.Add(Problem{Severity: "warn", File: "config/parameters/a.json", Key: "banner", Rule: "description-required", Message: "parameter has no description"})
+	c.report.Add(Problem{Severity: "error", File: "config/conditions/conditions.json", Key: "ios", Rule: "validation", Message: "duplicate condition ios"})
+	c.report.Add(Problem{Severity: "error", Rule: "remote-validation", Message: "error publishing template"})
+	c.report.Sort()
+}
+
+func (c *ReportTestSuite) write(format string) string {
+	out := &bytes.Buffer{}
+	assert.NoError(c.T(), c.report.Write(out, format))
+	return out.String()
+}
+
+func (c *ReportTestSuite) TestText() {
+	assert.Equal(c.T(), `error: error publishing template [remote-validation]
+error: config/conditions/conditions.json: ios: duplicate condition ios [validation]
+warn: config/parameters/a.json: banner: parameter has no description [description-required]
+`, c.write(FormatText))
+	assert.True(c.T(), c.report.HasErrors())
+	assert.Equal(c.T(), 1, c.report.Count("warn"))
+}
+
+func (c *ReportTestSuite) TestJSON() {
+	var decoded struct {
+		Errors   int
+		Warnings int
+		Problems []Problem
+	}
+	assert.NoError(c.T(), json.Unmarshal([]byte(c.write(FormatJSON)), &decoded))
+	assert.Equal(c.T(), 2, decoded.Errors)
+	assert.Equal(c.T(), 1, decoded.Warnings)
+	assert.Equal(c.T(), c.report.Problems, decoded.Problems)
+
+	empty := &bytes.Buffer{}
+	assert.NoError(c.T(), (&Report{}).Write(empty, FormatJSON))
+	assert.Contains(c.T(), empty.String(), `"problems": []`)
+}
+
+func (c *ReportTestSuite) TestJUnit() {
+	var suite junitSuite
+	assert.NoError(c.T(), xml.Unmarshal([]byte(c.write(FormatJUnit)), &suite))
+	assert.Equal(c.T(), 3, suite.Tests)
+	assert.Equal(c.T(), 2, suite.Failures)
+	assert.Equal(c.T(), "ios", suite.TestCases[1].Name)
+	assert.Equal(c.T(), "config/conditions/conditions.json", suite.TestCases[1].ClassName)
+	assert.Equal(c.T(), "duplicate condition ios", suite.TestCases[1].Failure.Message)
+	assert.Nil(c.T(), suite.TestCases[2].Failure)
+
+	empty := &bytes.Buffer{}
+	assert.NoError(c.T(), (&Report{}).Write(empty, FormatJUnit))
+	assert.Contains(c.T(), empty.String(), `tests="1" failures="0"`)
+}
+
+func (c *ReportTestSuite) TestSARIF() {
+	var log sarifLog
+	assert.NoError(c.T(), json.Unmarshal([]byte(c.write(FormatSARIF)), &log))
+	assert.Equal(c.T(), "2.1.0", log.Version)
+	run := log.Runs[0]
+	assert.Equal(c.T(), []sarifRule{{ID: "description-required"}, {ID: "remote-validation"}, {ID: "validation"}}, run.Tool.Driver.Rules)
+	assert.Len(c.T(), run.Results, 3)
+	assert.Empty(c.T(), run.Results[0].Locations)
+	assert.Equal(c.T(), "error", run.Results[1].Level)
+	assert.Equal(c.T(), "config/conditions/conditions.json", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(c.T(), "warning", run.Results[2].Level)
+}
+
+func (c *ReportTestSuite) TestGitHub() {
+	assert.Equal(c.T(), `::error title=remote-validation::error publishing template
+::error file=config/conditions/conditions.json,title=validation::ios: duplicate condition ios
+::warning file=config/parameters/a.json,title=description-required::banner: parameter has no description
+`, c.write(FormatGitHub))
+}
+
+func (c *ReportTestSuite) TestUnknownFormat() {
+	assert.EqualError(c.T(), c.report.Write(&bytes.Buffer{}, "html"), "unknown report format html, expected one of text, json, junit, sarif, github")
+}
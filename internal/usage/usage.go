@@ -0,0 +1,158 @@
+// Package usage finds the parameters of a config that application code refers to. A parameter counts as used when
+// any string literal in the scanned sources equals its key, so keys kept in constants are found too. Calls of the
+// Remote Config accessors of the client SDKs with a literal key are also checked against the config, which finds
+// code reading keys that do not exist. Accessors are only recognised on a Remote Config receiver, so reads of
+// preferences, bundles or other configs with the same method names are not mistaken for them.
+package usage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Extensions are the file extensions scanned: Kotlin, Swift, Go and TypeScript.
+var Extensions = []string{".kt", ".kts", ".swift", ".go", ".ts", ".tsx"}
+
+// skippedDirs hold dependencies and build output rather than application code.
+var skippedDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true, "build": true, "Pods": true, ".gradle": true}
+
+var literalPattern = regexp.MustCompile("\"((?:[^\"\\\\]|\\\\.)*)\"|'((?:[^'\\\\]|\\\\.)*)'|`([^`]*)`")
+
+// DefaultReceiver matches the usual ways of referring to the Remote Config client: remoteConfig, firebaseRemoteConfig,
+// Firebase.remoteConfig, RemoteConfig.remoteConfig() and FirebaseRemoteConfig.getInstance().
+const DefaultReceiver = `\w*[rR]emoteConfig\w*(?:\(\)|\.getInstance\(\))?`
+
+// accessorPatterns returns the patterns matching a Remote Config read with a literal key, captured in the first group,
+// on a receiver matching the regular expression receiver.
+func accessorPatterns(receiver string) ([]*regexp.Regexp, error) {
+	if _, err := regexp.Compile(receiver); err != nil {
+		return nil, fmt.Errorf("invalid receiver %s: %s", receiver, err.Error())
+	}
+	receiver = `\b(?:` + receiver + `)`
+	return []*regexp.Regexp{
+		// Kotlin and Java: remoteConfig.getString("key")
+		regexp.MustCompile(receiver + `\s*\.\s*(?:getString|getBoolean|getLong|getDouble|getValue)\s*\(\s*["']([^"']+)["']`),
+		// TypeScript: getValue(remoteConfig, "key")
+		regexp.MustCompile(`\b(?:getString|getBoolean|getNumber|getValue)\s*\(\s*` + receiver + `\s*,\s*["']([^"']+)["']`),
+		// Swift: remoteConfig.configValue(forKey: "key")
+		regexp.MustCompile(receiver + `\s*\.\s*configValue\s*\(\s*forKey:\s*"([^"]+)"`),
+		// Kotlin and Swift: remoteConfig["key"]
+		regexp.MustCompile(receiver + `\s*\[\s*["']([^"']+)["']\s*\]`),
+		// Go: remoteConfig.GetString("key")
+		regexp.MustCompile(receiver + `\.(?:GetString|GetBool|GetInt|GetInt64|GetFloat|GetFloat64|GetValue|GetJSON)\s*\(\s*"([^"]+)"`),
+	}, nil
+}
+
+// Reference is a place in the code that reads a key.
+type Reference struct {
+	File string
+	Line int
+	Key  string
+}
+
+// Report is the result of a scan.
+type Report struct {
+	// Unused are the keys no literal in the code is equal to.
+	Unused []string
+	// Unknown are the accessor calls reading keys that are not in the config.
+	Unknown []Reference
+	// FilesScanned is the number of source files read.
+	FilesScanned int
+}
+
+// Scan searches the sources under roots for the keys. Accessor calls are recognised on receivers matching the
+// regular expression receiver, or DefaultReceiver when it is empty.
+func Scan(fs afero.Fs, roots []string, keys []string, receiver string) (*Report, error) {
+	if receiver == "" {
+		receiver = DefaultReceiver
+	}
+	patterns, err := accessorPatterns(receiver)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, key := range keys {
+		known[key] = true
+	}
+	used := map[string]bool{}
+	report := &Report{Unused: []string{}, Unknown: []Reference{}}
+	for _, root := range roots {
+		err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != root && skippedDirs[info.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !scanned(path) {
+				return nil
+			}
+			report.FilesScanned++
+			return scanFile(fs, path, patterns, known, used, report)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range keys {
+		if !used[key] {
+			report.Unused = append(report.Unused, key)
+		}
+	}
+	sort.Strings(report.Unused)
+	sort.SliceStable(report.Unknown, func(i, j int) bool {
+		a, b := report.Unknown[i], report.Unknown[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report, nil
+}
+
+func scanned(path string) bool {
+	for _, extension := range Extensions {
+		if strings.HasSuffix(path, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+func scanFile(fs afero.Fs, path string, patterns []*regexp.Regexp, known, used map[string]bool, report *Report) error {
+	file, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, match := range literalPattern.FindAllStringSubmatch(text, -1) {
+			for _, literal := range match[1:] {
+				if known[literal] {
+					used[literal] = true
+				}
+			}
+		}
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+				if !known[match[1]] {
+					report.Unknown = append(report.Unknown, Reference{File: path, Line: line, Key: match[1]})
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UsageTestSuite struct {
+	suite.Suite
+	fs afero.Fs
+}
+
+func TestUsage(t *testing.T) {
+	suite.Run(t, new(UsageTestSuite))
+}
+
+func (c *UsageTestSuite) SetupTest() {
+	c.fs = afero.NewMemMapFs()
+	afero.WriteFile(c.fs, "/app/android/Flags.kt", []byte(`object Flags {
+    const val BANNER = "banner_text"
+    fun theme() = remoteConfig.getString("theme")
+    fun typo() = remoteConfig.getBoolean("new_chekout")
+    fun saved() = prefs.getString("last_screen", null) + intent.getStringExtra("source") + bundle.getBoolean("restored")
+    fun legacy() = FirebaseRemoteConfig.getInstance().getLong("legacy_limit")
+}`), 0644)
+	afero.WriteFile(c.fs, "/app/server/flags.go", []byte(`port := viper.GetString("http_port")
+theme := flags.GetString("theme_v2")`), 0644)
+	afero.WriteFile(c.fs, "/app/ios/Flags.swift", []byte(`let limit = remoteConfig.configValue(forKey: "payment_limit").numberValue
+let old = remoteConfig["removed_flag"].stringValue`), 0644)
+	afero.WriteFile(c.fs, "/app/web/flags.ts", []byte(`const v = getValue(remoteConfig, 'theme');`), 0644)
+	afero.WriteFile(c.fs, "/app/web/node_modules/lib/index.ts", []byte(`getString("unused_key")`), 0644)
+	afero.WriteFile(c.fs, "/app/README.md", []byte(`"unused_key"`), 0644)
+}
+
+func (c *UsageTestSuite) TestScan() {
+	report, err := Scan(c.fs, []string{"/app"}, []string{"banner_text", "theme", "payment_limit", "unused_key", "new_checkout"}, "")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), 4, report.FilesScanned)
+	assert.Equal(c.T(), []string{"new_checkout", "unused_key"}, report.Unused)
+	assert.Equal(c.T(), []Reference{
+		{File: "/app/android/Flags.kt", Line: 4, Key: "new_chekout"},
+		{File: "/app/android/Flags.kt", Line: 6, Key: "legacy_limit"},
+		{File: "/app/ios/Flags.swift", Line: 2, Key: "removed_flag"},
+	}, report.Unknown)
+}
+
+func (c *UsageTestSuite) TestScanWithReceiver() {
+	report, err := Scan(c.fs, []string{"/app/server"}, []string{"theme"}, "flags")
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []Reference{{File: "/app/server/flags.go", Line: 2, Key: "theme_v2"}}, report.Unknown)
+
+	_, err = Scan(c.fs, []string{"/app"}, nil, "(")
+	assert.Error(c.T(), err)
+}
+
+func (c *UsageTestSuite) TestMissingRoot() {
+	_, err := Scan(c.fs, []string{"/missing"}, nil, "")
+	assert.Error(c.T(), err)
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+	// ChangeMoved is a condition that is unchanged but at a different position, which changes its priority.
+	ChangeMoved ChangeType = "moved"
+)
+
+type ParameterChange struct {
+	Key    string
+	Type   ChangeType
+	Before *remoteconfig.Parameter
+	After  *remoteconfig.Parameter
+}
+
+type ConditionChange struct {
+	Name   string
+	Type   ChangeType
+	Before *remoteconfig.Condition
+	After  *remoteconfig.Condition
+}
+
+// ChangeSet is the difference between a template and the one it replaces, sorted by key and name.
+type ChangeSet struct {
+	Parameters []ParameterChange
+	Conditions []ConditionChange
+}
+
+// ComputeChanges returns the changes that replacing remote with target makes.
+// Parameters are compared regardless of the parameter group they are in.
+func ComputeChanges(target, remote remoteconfig.RemoteConfig) ChangeSet {
+	changes := ChangeSet{Parameters: []ParameterChange{}, Conditions: []ConditionChange{}}
+
+	before, after := flattenParameters(remote), flattenParameters(target)
+	for _, key := range unionKeys(before, after) {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		switch {
+		case !inBefore:
+			changes.Parameters = append(changes.Parameters, ParameterChange{Key: key, Type: ChangeAdded, After: &a})
+		case !inAfter:
+			changes.Parameters = append(changes.Parameters, ParameterChange{Key: key, Type: ChangeDeleted, Before: &b})
+		case !remoteParametersEqual(a, b):
+			changes.Parameters = append(changes.Parameters, ParameterChange{Key: key, Type: ChangeUpdated, Before: &b, After: &a})
+		}
+	}
+
+	beforeIndex, afterIndex := map[string]int{}, map[string]int{}
+	for i, condition := range remote.Conditions {
+		beforeIndex[condition.Name] = i
+	}
+	for i, condition := range target.Conditions {
+		afterIndex[condition.Name] = i
+	}
+	// a condition has moved if its position among the conditions present in both templates has changed
+	remoteRank, targetRank := commonRanks(remote.Conditions, afterIndex), commonRanks(target.Conditions, beforeIndex)
+	names := []string{}
+	for name := range beforeIndex {
+		names = append(names, name)
+	}
+	for name := range afterIndex {
+		if _, ok := beforeIndex[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i, inBefore := beforeIndex[name]
+		j, inAfter := afterIndex[name]
+		switch {
+		case !inBefore:
+			a := target.Conditions[j]
+			changes.Conditions = append(changes.Conditions, ConditionChange{Name: name, Type: ChangeAdded, After: &a})
+		case !inAfter:
+			b := remote.Conditions[i]
+			changes.Conditions = append(changes.Conditions, ConditionChange{Name: name, Type: ChangeDeleted, Before: &b})
+		case remote.Conditions[i] != target.Conditions[j]:
+			b, a := remote.Conditions[i], target.Conditions[j]
+			changes.Conditions = append(changes.Conditions, ConditionChange{Name: name, Type: ChangeUpdated, Before: &b, After: &a})
+		case remoteRank[name] != targetRank[name]:
+			b, a := remote.Conditions[i], target.Conditions[j]
+			changes.Conditions = append(changes.Conditions, ConditionChange{Name: name, Type: ChangeMoved, Before: &b, After: &a})
+		}
+	}
+	return changes
+}
+
+func (c ChangeSet) IsEmpty() bool {
+	return c.Count() == 0
+}
+
+func (c ChangeSet) Count() int {
+	return len(c.Parameters) + len(c.Conditions)
+}
+
+// Summary counts the changes by type, such as "2 added, 1 updated".
+func (c ChangeSet) Summary() string {
+	counts := map[ChangeType]int{}
+	for _, change := range c.Parameters {
+		counts[change.Type]++
+	}
+	for _, change := range c.Conditions {
+		counts[change.Type]++
+	}
+	parts := []string{}
+	for _, changeType := range []ChangeType{ChangeAdded, ChangeUpdated, ChangeDeleted, ChangeMoved} {
+		if counts[changeType] != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[changeType], changeType))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Deletions returns the keys of deleted parameters and the names of deleted conditions.
+func (c ChangeSet) Deletions() (parameters []string, conditions []string) {
+	for _, change := range c.Parameters {
+		if change.Type == ChangeDeleted {
+			parameters = append(parameters, change.Key)
+		}
+	}
+	for _, change := range c.Conditions {
+		if change.Type == ChangeDeleted {
+			conditions = append(conditions, change.Name)
+		}
+	}
+	return parameters, conditions
+}
+
+// Safeguards limit the changes an apply is allowed to make.
+type Safeguards struct {
+	// AllowDeletes permits deleting parameters and conditions.
+	AllowDeletes bool
+	// MaxChanges is the maximum number of changed parameters and conditions, unlimited when 0.
+	MaxChanges int
+	// Protected parameters can never be deleted.
+	Protected []string
+}
+
+// Check returns an error describing every safeguard the change set violates.
+func (c ChangeSet) Check(s Safeguards) error {
+	var problems []string
+	deletedParameters, deletedConditions := c.Deletions()
+	protected := map[string]bool{}
+	for _, key := range s.Protected {
+		protected[key] = true
+	}
+	for _, key := range deletedParameters {
+		if protected[key] {
+			problems = append(problems, fmt.Sprintf("parameter %s is protected and cannot be deleted", key))
+		}
+	}
+	if !s.AllowDeletes && len(deletedParameters)+len(deletedConditions) != 0 {
+		if len(deletedParameters) != 0 {
+			problems = append(problems, fmt.Sprintf("would delete parameters: %s", strings.Join(deletedParameters, ", ")))
+		}
+		if len(deletedConditions) != 0 {
+			problems = append(problems, fmt.Sprintf("would delete conditions: %s", strings.Join(deletedConditions, ", ")))
+		}
+		problems = append(problems, "pass --allow-deletes to apply deletions")
+	}
+	if s.MaxChanges > 0 && c.Count() > s.MaxChanges {
+		problems = append(problems, fmt.Sprintf("%d changes exceed the limit of %d changes per apply", c.Count(), s.MaxChanges))
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+// commonRanks numbers the conditions that are also in other, in their order in conditions.
+func commonRanks(conditions []remoteconfig.Condition, other map[string]int) map[string]int {
+	ranks := map[string]int{}
+	for _, condition := range conditions {
+		if _, ok := other[condition.Name]; ok {
+			ranks[condition.Name] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+func flattenParameters(rc remoteconfig.RemoteConfig) map[string]remoteconfig.Parameter {
+	parameters := map[string]remoteconfig.Parameter{}
+	for key, parameter := range rc.Parameters {
+		parameters[key] = parameter
+	}
+	for _, group := range rc.ParameterGroups {
+		for key, parameter := range group.Parameters {
+			if parameter != nil {
+				parameters[key] = *parameter
+			}
+		}
+	}
+	return parameters
+}
+
+func unionKeys(a, b map[string]remoteconfig.Parameter) []string {
+	keys := []string{}
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func remoteParametersEqual(a, b remoteconfig.Parameter) bool {
+	if a.Description != b.Description || !reflect.DeepEqual(a.DefaultValue, b.DefaultValue) {
+		return false
+	}
+	if len(a.ConditionalValues) == 0 && len(b.ConditionalValues) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.ConditionalValues, b.ConditionalValues)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChangesTestSuite struct {
+	suite.Suite
+}
+
+func TestChanges(t *testing.T) {
+	suite.Run(t, new(ChangesTestSuite))
+}
+
+func (c *ChangesTestSuite) TestComputeChanges() {
+	remote := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "gone"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"same":    {DefaultValue: remoteValue("1"), ConditionalValues: map[string]*remoteconfig.ParameterValue{}},
+			"updated": {DefaultValue: remoteValue("1")},
+			"deleted": {DefaultValue: remoteValue("1")},
+		},
+	}
+	target := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "b"}, {Name: "a"}, {Name: "c", Expression: "x"}, {Name: "new"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"same":    {DefaultValue: remoteValue("1")},
+			"updated": {DefaultValue: remoteValue("2")},
+			"added":   {DefaultValue: remoteValue("1")},
+		},
+	}
+	changes := ComputeChanges(target, remote)
+
+	types := map[string]ChangeType{}
+	for _, change := range changes.Parameters {
+		types[change.Key] = change.Type
+	}
+	assert.Equal(c.T(), map[string]ChangeType{"added": ChangeAdded, "updated": ChangeUpdated, "deleted": ChangeDeleted}, types)
+	types = map[string]ChangeType{}
+	for _, change := range changes.Conditions {
+		types[change.Name] = change.Type
+	}
+	assert.Equal(c.T(), map[string]ChangeType{"a": ChangeMoved, "b": ChangeMoved, "c": ChangeUpdated, "gone": ChangeDeleted, "new": ChangeAdded}, types)
+	assert.Equal(c.T(), "2 added, 2 updated, 2 deleted, 2 moved", changes.Summary())
+	assert.True(c.T(), ComputeChanges(remote, remote).IsEmpty())
+	assert.Equal(c.T(), "no changes", ComputeChanges(remote, remote).Summary())
+}
+
+func (c *ChangesTestSuite) TestSafeguards() {
+	changes := ChangeSet{
+		Parameters: []ParameterChange{{Key: "kept", Type: ChangeUpdated}, {Key: "important", Type: ChangeDeleted}},
+		Conditions: []ConditionChange{{Name: "old", Type: ChangeDeleted}},
+	}
+	err := changes.Check(Safeguards{})
+	assert.Contains(c.T(), err.Error(), "would delete parameters: important")
+	assert.Contains(c.T(), err.Error(), "would delete conditions: old")
+
+	assert.NoError(c.T(), changes.Check(Safeguards{AllowDeletes: true}))
+
+	err = changes.Check(Safeguards{AllowDeletes: true, Protected: []string{"important"}})
+	assert.Contains(c.T(), err.Error(), "parameter important is protected")
+
+	err = changes.Check(Safeguards{AllowDeletes: true, MaxChanges: 2})
+	assert.Contains(c.T(), err.Error(), "3 changes exceed the limit of 2")
+}
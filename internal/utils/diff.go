@@ -1,30 +1,55 @@
 package utils
 
 import (
-	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
 	"strings"
 )
 
-func PrintDiff(source, remote remoteconfig.RemoteConfig) {
+// FormatDiff renders the changes of a change set as colored diffs, conditions first, with the values of secret
+// parameters masked.
+func FormatDiff(changes ChangeSet) string {
+	sb := strings.Builder{}
+	sb.WriteString("Generating diff for conditions\n")
+	for _, change := range changes.Conditions {
+		sb.WriteString(formatChangeLine(change.Type, change.Name))
+		sb.WriteString(colorize(conditionChangeDiff(change)) + "\n")
+	}
+	sb.WriteString(diffSeparator + "\n")
+	sb.WriteString("Generating diff for parameters\n")
+	for _, change := range changes.Parameters {
+		sb.WriteString(formatChangeLine(change.Type, change.Key))
+		sb.WriteString(colorize(parameterChangeDiff(change)) + "\n")
+	}
+	sb.WriteString(diffSeparator + "\n")
+	return sb.String()
+}
 
-	fmt.Println("Generating diff for conditions")
-	fmt.Println(GetRemoteDiffForConditions(source.Conditions, remote.Conditions))
-	sParams, rParams := map[string]remoteconfig.Parameter{}, map[string]remoteconfig.Parameter{}
-	for k, v := range source.Parameters {
-		sParams[k] = v
+func conditionChangeDiff(change ConditionChange) string {
+	source, remote := []remoteconfig.Condition{}, []remoteconfig.Condition{}
+	if change.After != nil {
+		source = append(source, *change.After)
 	}
-	for i := range remote.Parameters {
-		rParams[i] = remote.Parameters[i]
+	if change.Before != nil {
+		remote = append(remote, *change.Before)
 	}
-	fmt.Println("------------------------------------------------------------------------------------------------------------------------")
-	fmt.Println("Generating diff for parameters")
-	fmt.Println(GetRemoteDiffForParameters(sParams, rParams))
-	fmt.Println("------------------------------------------------------------------------------------------------------------------------")
+	return cmp.Diff(remote, source)
+}
 
+func parameterChangeDiff(change ParameterChange) string {
+	source, remote := map[string]remoteconfig.Parameter{}, map[string]remoteconfig.Parameter{}
+	if change.After != nil {
+		source[change.Key] = *change.After
+	}
+	if change.Before != nil {
+		remote[change.Key] = *change.Before
+	}
+	return parameterDiff(source, remote)
 }
 
+const diffSeparator = "------------------------------------------------------------------------------------------------------------------------"
+
 const (
 	Reset = "\u001B[0m"
 	Red   = "\033[1;31m"
@@ -33,41 +58,60 @@ const (
 )
 
 func GetRemoteDiffForConditions(source, remote []remoteconfig.Condition) string {
-	diff := cmp.Diff(remote, source)
-	greenDiff := strings.ReplaceAll(diff, "\n+", "\n"+Green)
-	redDiff := strings.ReplaceAll(greenDiff, "\n-", "\n"+Red)
-	finalDiff := strings.ReplaceAll(redDiff, "\n", Reset+"\n")
-	return finalDiff
+	return colorize(cmp.Diff(remote, source))
 }
 
 func GetRemoteDiffForParameters(source, remote map[string]remoteconfig.Parameter) string {
-	diff := cmp.Diff(remote, source)
-	maskedString := maskSecrets(diff)
-	greenDiff := strings.ReplaceAll(maskedString, "\n+", "\n"+Green)
+	return colorize(parameterDiff(source, remote))
+}
+
+// parameterDiff returns the uncolored diff between parameters, with the values of secret parameters masked.
+func parameterDiff(source, remote map[string]remoteconfig.Parameter) string {
+	return cmp.Diff(maskParameters(remote), maskParameters(source))
+}
+
+func colorize(diff string) string {
+	greenDiff := strings.ReplaceAll(diff, "\n+", "\n"+Green)
 	redDiff := strings.ReplaceAll(greenDiff, "\n-", "\n"+Red)
 	finalDiff := strings.ReplaceAll(redDiff, "\n", Reset+"\n")
 	return finalDiff
 }
 
-func maskSecrets(input string) string {
-    lines := strings.Split(input, "\n")
-    maskedOutput := ""
-    isMultiLineSecretJson := false
+// MaskedValue replaces the values of secret parameters wherever they are shown.
+const MaskedValue = "*******"
+
+// IsSecretKey reports whether the parameter with the given key holds a secret.
+func IsSecretKey(key string) bool {
+	return strings.HasPrefix(key, config.SecretParameterPrefix)
+}
+
+// maskParameters returns parameters with the values of secret parameters replaced by MaskedValue.
+func maskParameters(parameters map[string]remoteconfig.Parameter) map[string]remoteconfig.Parameter {
+	masked := make(map[string]remoteconfig.Parameter, len(parameters))
+	for key, parameter := range parameters {
+		if IsSecretKey(key) {
+			parameter = maskParameter(parameter)
+		}
+		masked[key] = parameter
+	}
+	return masked
+}
 
-    for _, line := range lines {
-        keyValue := strings.SplitN(line, ":", 2)
-        if len(keyValue) > 1 {
-            if isMultiLineSecretJson || strings.Contains(line, "SEC_") {
-                keyValue[1] = "*******"
-                if strings.Contains(line, "ExplicitValue"){
-                    isMultiLineSecretJson = false
-                }else{
-                    isMultiLineSecretJson = true
-                }
-            }
-        }
-        maskedOutput += "\n" + strings.Join(keyValue, ":")
-    }
+func maskParameter(parameter remoteconfig.Parameter) remoteconfig.Parameter {
+	parameter.DefaultValue = maskValue(parameter.DefaultValue)
+	if parameter.ConditionalValues != nil {
+		conditionalValues := make(map[string]*remoteconfig.ParameterValue, len(parameter.ConditionalValues))
+		for name, value := range parameter.ConditionalValues {
+			conditionalValues[name] = maskValue(value)
+		}
+		parameter.ConditionalValues = conditionalValues
+	}
+	return parameter
+}
 
-    return maskedOutput
+func maskValue(value *remoteconfig.ParameterValue) *remoteconfig.ParameterValue {
+	if value == nil || value.UseInAppDefault {
+		return value
+	}
+	return &remoteconfig.ParameterValue{ExplicitValue: MaskedValue}
 }
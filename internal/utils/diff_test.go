@@ -4,7 +4,9 @@ import (
 	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 type DiffTestSuite struct {
@@ -50,6 +52,101 @@ func (c *DiffTestSuite) TestConditionsDiff() {
 	assert.Equal(c.T(), "", diff)
 }
 
+func (c *DiffTestSuite) TestFormatDiff() {
+	secret := remoteconfig.Parameter{DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "hunter2"}}
+	changes := ComputeChanges(remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_token": secret,
+			"theme":     {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "dark"}},
+		},
+	}, remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"theme": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "light"}},
+		},
+	})
+	diff := FormatDiff(changes)
+	assert.Contains(c.T(), diff, "+ ios")
+	assert.Contains(c.T(), diff, "device.os == 'ios'")
+	assert.Contains(c.T(), diff, "+ SEC_token")
+	assert.Contains(c.T(), diff, "~ theme")
+	assert.Contains(c.T(), diff, "dark")
+	assert.NotContains(c.T(), diff, "hunter2")
+	assert.Less(c.T(), strings.Index(diff, "ios"), strings.Index(diff, "theme"))
+}
+
+func (c *DiffTestSuite) TestFormatDiffMasksUpdatedSecrets() {
+	changes := ComputeChanges(remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_TOKEN": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "newsecret"}, Description: "api token"},
+		},
+	}, remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_TOKEN": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "oldsecret"}},
+		},
+	})
+	diff := FormatDiff(changes)
+	assert.Contains(c.T(), diff, "~ SEC_TOKEN")
+	assert.Contains(c.T(), diff, "api token")
+	assert.NotContains(c.T(), diff, "newsecret")
+	assert.NotContains(c.T(), diff, "oldsecret")
+}
+
 func Test_Suite(t *testing.T) {
 	suite.Run(t, new(DiffTestSuite))
 }
+
+func (c *DiffTestSuite) TestFormatMarkdown() {
+	changes := ComputeChanges(remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_token": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "hunter2"}},
+		},
+	}, remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"theme": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "```"}},
+		},
+	})
+	markdown := FormatMarkdown("Remote Config changes", changes, 0)
+	assert.True(c.T(), strings.HasPrefix(markdown, "### Remote Config changes\n\n| | Add | Update | Delete | Move |\n"))
+	assert.Contains(c.T(), markdown, "| Parameters | 1 | 0 | 1 | 0 |\n| Conditions | 1 | 0 | 0 | 0 |\n")
+	assert.Contains(c.T(), markdown, "<summary>add condition <code>ios</code></summary>")
+	assert.Contains(c.T(), markdown, "<summary>delete parameter <code>theme</code></summary>\n\n````diff\n")
+	assert.NotContains(c.T(), markdown, "hunter2")
+	assert.NotContains(c.T(), markdown, "\u001b")
+
+	assert.Equal(c.T(), "### Remote Config changes\n\nNo changes.\n", FormatMarkdown("Remote Config changes", ChangeSet{}, 0))
+
+	length := utf8.RuneCountInString(markdown)
+	truncated := FormatMarkdown("Remote Config changes", changes, length-1)
+	assert.Contains(c.T(), truncated, "<summary>add condition <code>ios</code></summary>")
+	assert.NotContains(c.T(), truncated, "<summary>delete parameter <code>theme</code></summary>")
+	assert.True(c.T(), strings.HasSuffix(truncated, "_Diff truncated, 1 more changes are not shown. Run `firebase-ctl diff` for the full diff._\n"))
+	assert.LessOrEqual(c.T(), utf8.RuneCountInString(truncated), length-1)
+}
+
+func (c *DiffTestSuite) TestFormatMarkdownMasksUpdatedSecrets() {
+	changes := ComputeChanges(remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_TOKEN": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "newsecret"}},
+			"SEC_KEY": {
+				DefaultValue:      &remoteconfig.ParameterValue{ExplicitValue: "default"},
+				ConditionalValues: map[string]*remoteconfig.ParameterValue{"ios": {ExplicitValue: "newioskey"}},
+			},
+		},
+	}, remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"SEC_TOKEN": {DefaultValue: &remoteconfig.ParameterValue{ExplicitValue: "oldsecret"}},
+			"SEC_KEY": {
+				DefaultValue:      &remoteconfig.ParameterValue{ExplicitValue: "default"},
+				ConditionalValues: map[string]*remoteconfig.ParameterValue{"ios": {ExplicitValue: "oldioskey"}},
+			},
+		},
+	})
+	markdown := FormatMarkdown("Remote Config changes", changes, 0)
+	assert.Contains(c.T(), markdown, "<summary>update parameter <code>SEC_TOKEN</code></summary>")
+	assert.Contains(c.T(), markdown, "<summary>update parameter <code>SEC_KEY</code></summary>")
+	for _, value := range []string{"newsecret", "oldsecret", "newioskey", "oldioskey", "default"} {
+		assert.NotContains(c.T(), markdown, value)
+	}
+}
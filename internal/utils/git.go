@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDescriptionLength is the longest version description the Remote Config API accepts, in characters.
+const maxDescriptionLength = 256
+
+// GitInfo describes the state of the git work tree a template is published from.
+type GitInfo struct {
+	Commit string
+	Branch string
+	Author string
+	Dirty  bool
+}
+
+// GetGitInfo returns the git state of the work tree containing dir, or nil when dir is not inside a git work tree.
+func GetGitInfo(dir string) *GitInfo {
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	branch, _ := git(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	author, _ := git(dir, "log", "-1", "--format=%an")
+	status, _ := git(dir, "status", "--porcelain", "--", ".")
+	return &GitInfo{Commit: commit, Branch: branch, Author: author, Dirty: status != ""}
+}
+
+// CurrentUser returns the name of the person running firebase-ctl: the git user.name configured for dir, or the
+// login name when there is none.
+func CurrentUser(dir string) string {
+	if name, err := git(dir, "config", "user.name"); err == nil && name != "" {
+		return name
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
+func git(dir string, args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// VersionDescription builds the description of a published template version from message and the git state.
+// The message is shortened so that the git metadata always fits in the description.
+func VersionDescription(message string, info *GitInfo) string {
+	if message == "" {
+		message = "applied by firebase-ctl"
+	}
+	if info == nil {
+		return truncate(message, maxDescriptionLength)
+	}
+	metadata := fmt.Sprintf("commit:%s", info.Commit)
+	if info.Branch != "" && info.Branch != "HEAD" {
+		metadata += fmt.Sprintf(" branch:%s", info.Branch)
+	}
+	if info.Dirty {
+		metadata += " dirty"
+	}
+	if info.Author != "" {
+		metadata += fmt.Sprintf(" author:%s", info.Author)
+	}
+	metadata = truncate(metadata, maxDescriptionLength-len(" | ")-1)
+	return truncate(message, maxDescriptionLength-utf8.RuneCountInString(metadata)-len(" | ")) + " | " + metadata
+}
+
+var commitPattern = regexp.MustCompile(`\bcommit:([0-9a-f]{7,40})\b`)
+
+// CommitFromDescription returns the commit recorded in a version description by VersionDescription, if any.
+func CommitFromDescription(description string) string {
+	match := commitPattern.FindStringSubmatch(description)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// truncate shortens s to length characters, ending it with an ellipsis when it was cut.
+func truncate(s string, length int) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	runes := []rune(s)
+	if length <= 3 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-3]) + "..."
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GitTestSuite struct {
+	suite.Suite
+}
+
+func TestGit(t *testing.T) {
+	suite.Run(t, new(GitTestSuite))
+}
+
+func (c *GitTestSuite) TestVersionDescription() {
+	info := &GitInfo{Commit: "0123456789abcdef0123456789abcdef01234567", Branch: "main", Author: "Jane Doe", Dirty: true}
+	description := VersionDescription("raise payment limit", info)
+	assert.Equal(c.T(), "raise payment limit | commit:0123456789abcdef0123456789abcdef01234567 branch:main dirty author:Jane Doe", description)
+	assert.Equal(c.T(), info.Commit, CommitFromDescription(description))
+
+	assert.Equal(c.T(), "applied by firebase-ctl", VersionDescription("", nil))
+	assert.Equal(c.T(), "", CommitFromDescription("edited in the console"))
+}
+
+func (c *GitTestSuite) TestLongMessagesKeepTheCommit() {
+	info := &GitInfo{Commit: "0123456789abcdef0123456789abcdef01234567"}
+	description := VersionDescription(strings.Repeat("x", 300), info)
+	assert.Len(c.T(), description, maxDescriptionLength)
+	assert.Equal(c.T(), info.Commit, CommitFromDescription(description))
+}
+
+func (c *GitTestSuite) TestTruncateKeepsRunesWhole() {
+	info := &GitInfo{Commit: "0123456789abcdef0123456789abcdef01234567"}
+	description := VersionDescription(strings.Repeat("é", 300), info)
+	assert.True(c.T(), utf8.ValidString(description))
+	assert.Equal(c.T(), maxDescriptionLength, utf8.RuneCountInString(description))
+	assert.Equal(c.T(), "éé", truncate("ééé", 2))
+}
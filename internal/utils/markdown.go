@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatMarkdown renders a change set for a pull request comment: a table counting the changes, followed by a
+// collapsed diff per change with the values of secret parameters masked. With maxLength set, the diffs that would make
+// the result longer than maxLength characters are left out and a note says how many are missing.
+func FormatMarkdown(title string, changes ChangeSet, maxLength int) string {
+	sb := strings.Builder{}
+	sb.WriteString("### " + title + "\n\n")
+	if changes.IsEmpty() {
+		sb.WriteString("No changes.\n")
+		return sb.String()
+	}
+	parameterCounts, conditionCounts := map[ChangeType]int{}, map[ChangeType]int{}
+	for _, change := range changes.Parameters {
+		parameterCounts[change.Type]++
+	}
+	for _, change := range changes.Conditions {
+		conditionCounts[change.Type]++
+	}
+	sb.WriteString("| | Add | Update | Delete | Move |\n|---|---|---|---|---|\n")
+	for _, row := range []struct {
+		name   string
+		counts map[ChangeType]int
+	}{{"Parameters", parameterCounts}, {"Conditions", conditionCounts}} {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d |\n", row.name,
+			row.counts[ChangeAdded], row.counts[ChangeUpdated], row.counts[ChangeDeleted], row.counts[ChangeMoved])
+	}
+	sections := []string{}
+	for _, change := range changes.Conditions {
+		sections = append(sections, markdownChange(change.Type, "condition", change.Name, conditionChangeDiff(change)))
+	}
+	for _, change := range changes.Parameters {
+		sections = append(sections, markdownChange(change.Type, "parameter", change.Key, parameterChangeDiff(change)))
+	}
+	length := utf8.RuneCountInString(sb.String())
+	for i, section := range sections {
+		if maxLength > 0 {
+			note := fmt.Sprintf("\n_Diff truncated, %d more changes are not shown. Run `firebase-ctl diff` for the full diff._\n", len(sections)-i)
+			rest := utf8.RuneCountInString(section)
+			if i < len(sections)-1 {
+				// the note must still fit after this section
+				rest += utf8.RuneCountInString(note)
+			}
+			if length+rest > maxLength {
+				sb.WriteString(note)
+				break
+			}
+		}
+		sb.WriteString(section)
+		length += utf8.RuneCountInString(section)
+	}
+	return sb.String()
+}
+
+var changeVerbs = map[ChangeType]string{
+	ChangeAdded:   "add",
+	ChangeUpdated: "update",
+	ChangeDeleted: "delete",
+	ChangeMoved:   "move",
+}
+
+func markdownChange(changeType ChangeType, kind, name, diff string) string {
+	fence := "```"
+	for strings.Contains(diff, fence) {
+		fence += "`"
+	}
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "\n<details><summary>%s %s <code>%s</code></summary>\n\n", changeVerbs[changeType], kind, html.EscapeString(name))
+	fmt.Fprintf(&sb, "%sdiff\n%s\n%s\n\n</details>\n", fence, strings.Trim(diff, "\n"), fence)
+	return sb.String()
+}
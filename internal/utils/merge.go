@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+const (
+	ParameterKind = "parameter"
+	ConditionKind = "condition"
+)
+
+// Conflict is a parameter or condition that changed both locally and on remote since the last pull.
+type Conflict struct {
+	Kind   string
+	Key    string
+	Reason string
+}
+
+// MergeResult is the outcome of merging a remote config into the local sources.
+type MergeResult struct {
+	Parameters map[string]model.Parameter
+	Conditions []model.Condition
+	// Base is the snapshot to record as the last pull. Conflicting entries keep their previous base,
+	// so that they are reported again on the next pull instead of being resolved in favour of local.
+	Base      model.Config
+	Updated   []string
+	Conflicts []Conflict
+}
+
+// MergeRemote performs a three-way merge of remote into local, using base as the common ancestor.
+// Without a base every entry that differs between local and remote is a conflict.
+func MergeRemote(base *model.Config, local, remote model.Config) MergeResult {
+	result := MergeResult{
+		Parameters: map[string]model.Parameter{},
+		Base: model.Config{
+			Conditions: []model.Condition{},
+			Parameters: map[string]model.Parameter{},
+		},
+	}
+	mergeParameters(base, local, remote, &result)
+	mergeConditions(base, local, remote, &result)
+	sort.Strings(result.Updated)
+	sort.Slice(result.Conflicts, func(i, j int) bool {
+		if result.Conflicts[i].Kind != result.Conflicts[j].Kind {
+			return result.Conflicts[i].Kind > result.Conflicts[j].Kind
+		}
+		return result.Conflicts[i].Key < result.Conflicts[j].Key
+	})
+	return result
+}
+
+func mergeParameters(base *model.Config, local, remote model.Config, result *MergeResult) {
+	keys := map[string]bool{}
+	for key := range local.Parameters {
+		keys[key] = true
+	}
+	for key := range remote.Parameters {
+		keys[key] = true
+	}
+	if base != nil {
+		for key := range base.Parameters {
+			keys[key] = true
+		}
+	}
+	for key := range keys {
+		l, inLocal := local.Parameters[key]
+		r, inRemote := remote.Parameters[key]
+		var b model.Parameter
+		inBase := false
+		if base != nil {
+			b, inBase = base.Parameters[key]
+		}
+		remoteWins := false
+		conflict := ""
+		switch {
+		case inLocal == inRemote && (!inLocal || ParametersEqual(l, r)):
+		case base != nil && inLocal == inBase && (!inLocal || ParametersEqual(l, b)):
+			remoteWins = true
+		case base != nil && inRemote == inBase && (!inRemote || ParametersEqual(r, b)):
+		default:
+			conflict = conflictReason(inLocal, inRemote, base != nil)
+		}
+
+		if conflict != "" {
+			result.Conflicts = append(result.Conflicts, Conflict{Kind: ParameterKind, Key: key, Reason: conflict})
+			if inBase {
+				result.Base.Parameters[key] = b
+			}
+		} else if inRemote {
+			result.Base.Parameters[key] = r
+		}
+
+		if remoteWins {
+			result.Updated = append(result.Updated, key)
+			if inRemote {
+				if inLocal {
+					r.ValueType = l.ValueType
+				}
+				result.Parameters[key] = r
+			}
+			continue
+		}
+		if inLocal {
+			result.Parameters[key] = l
+		}
+	}
+}
+
+func mergeConditions(base *model.Config, local, remote model.Config, result *MergeResult) {
+	localByName := conditionsByName(local.Conditions)
+	remoteByName := conditionsByName(remote.Conditions)
+	baseByName := map[string]model.Condition{}
+	if base != nil {
+		baseByName = conditionsByName(base.Conditions)
+	}
+
+	merged := map[string]model.Condition{}
+	keep := map[string]bool{}
+	conflicting := map[string]bool{}
+	names := map[string]bool{}
+	for name := range localByName {
+		names[name] = true
+	}
+	for name := range remoteByName {
+		names[name] = true
+	}
+	for name := range baseByName {
+		names[name] = true
+	}
+	for name := range names {
+		l, inLocal := localByName[name]
+		r, inRemote := remoteByName[name]
+		b, inBase := baseByName[name]
+		switch {
+		case inLocal == inRemote && (!inLocal || l == r):
+			if inLocal {
+				merged[name], keep[name] = l, true
+			}
+		case base != nil && inLocal == inBase && (!inLocal || l == b):
+			result.Updated = append(result.Updated, name)
+			if inRemote {
+				merged[name], keep[name] = r, true
+			}
+		case base != nil && inRemote == inBase && (!inRemote || r == b):
+			if inLocal {
+				merged[name], keep[name] = l, true
+			}
+		default:
+			conflicting[name] = true
+			result.Conflicts = append(result.Conflicts, Conflict{Kind: ConditionKind, Key: name, Reason: conflictReason(inLocal, inRemote, base != nil)})
+			if inLocal {
+				merged[name], keep[name] = l, true
+			}
+		}
+	}
+
+	// Keep the local order, inserting conditions that only exist on remote right after their remote predecessor.
+	conditions := []model.Condition{}
+	for _, condition := range local.Conditions {
+		if keep[condition.Name] {
+			conditions = append(conditions, merged[condition.Name])
+		}
+	}
+	for i, condition := range remote.Conditions {
+		if _, inLocal := localByName[condition.Name]; inLocal || !keep[condition.Name] {
+			continue
+		}
+		position := 0
+		for j := i - 1; j >= 0 && position == 0; j-- {
+			for k := range conditions {
+				if conditions[k].Name == remote.Conditions[j].Name {
+					position = k + 1
+					break
+				}
+			}
+		}
+		conditions = append(conditions, model.Condition{})
+		copy(conditions[position+1:], conditions[position:])
+		conditions[position] = merged[condition.Name]
+	}
+	result.Conditions = conditions
+
+	for _, condition := range remote.Conditions {
+		if !conflicting[condition.Name] {
+			result.Base.Conditions = append(result.Base.Conditions, condition)
+		}
+	}
+	for _, condition := range baseConditions(base) {
+		if conflicting[condition.Name] {
+			result.Base.Conditions = append(result.Base.Conditions, condition)
+		}
+	}
+}
+
+func baseConditions(base *model.Config) []model.Condition {
+	if base == nil {
+		return nil
+	}
+	return base.Conditions
+}
+
+func conditionsByName(conditions []model.Condition) map[string]model.Condition {
+	byName := map[string]model.Condition{}
+	for _, condition := range conditions {
+		byName[condition.Name] = condition
+	}
+	return byName
+}
+
+func conflictReason(inLocal, inRemote, hasBase bool) string {
+	switch {
+	case !hasBase:
+		return "differs between local and remote and there is no previous pull to compare with"
+	case !inLocal:
+		return "deleted locally and changed on remote"
+	case !inRemote:
+		return "changed locally and deleted on remote"
+	default:
+		return "changed both locally and on remote"
+	}
+}
+
+// ParametersEqual reports whether two parameters are equal in everything that is published to remote.
+func ParametersEqual(a, b model.Parameter) bool {
+	if a.Description != b.Description {
+		return false
+	}
+	if !reflect.DeepEqual(a.DefaultValue, b.DefaultValue) {
+		return false
+	}
+	if len(a.ConditionalValues) == 0 && len(b.ConditionalValues) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.ConditionalValues, b.ConditionalValues)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MergeTestSuite struct {
+	suite.Suite
+}
+
+func TestMerge(t *testing.T) {
+	suite.Run(t, new(MergeTestSuite))
+}
+
+func parameter(value string) model.Parameter {
+	return model.Parameter{DefaultValue: &model.ParameterValue{ExplicitValue: value}, ValueType: "string"}
+}
+
+func (c *MergeTestSuite) TestParameters() {
+	base := &model.Config{Parameters: map[string]model.Parameter{
+		"remoteChanged": parameter("1"),
+		"localChanged":  parameter("1"),
+		"bothChanged":   parameter("1"),
+		"remoteDeleted": parameter("1"),
+		"localDeleted":  parameter("1"),
+	}}
+	local := model.Config{Parameters: map[string]model.Parameter{
+		"remoteChanged": parameter("1"),
+		"localChanged":  parameter("2"),
+		"bothChanged":   parameter("2"),
+		"remoteDeleted": parameter("1"),
+		"localAdded":    parameter("1"),
+	}}
+	remote := model.Config{Parameters: map[string]model.Parameter{
+		"remoteChanged": parameter("2"),
+		"localChanged":  parameter("1"),
+		"bothChanged":   parameter("3"),
+		"localDeleted":  parameter("1"),
+		"remoteAdded":   parameter("1"),
+	}}
+	result := MergeRemote(base, local, remote)
+
+	assert.Equal(c.T(), "2", result.Parameters["remoteChanged"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "2", result.Parameters["localChanged"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "2", result.Parameters["bothChanged"].DefaultValue.ExplicitValue)
+	assert.NotContains(c.T(), result.Parameters, "remoteDeleted")
+	assert.NotContains(c.T(), result.Parameters, "localDeleted")
+	assert.Contains(c.T(), result.Parameters, "localAdded")
+	assert.Contains(c.T(), result.Parameters, "remoteAdded")
+	assert.Equal(c.T(), []string{"remoteAdded", "remoteChanged", "remoteDeleted"}, result.Updated)
+	assert.Equal(c.T(), []Conflict{{Kind: ParameterKind, Key: "bothChanged", Reason: "changed both locally and on remote"}}, result.Conflicts)
+	assert.Equal(c.T(), "1", result.Base.Parameters["bothChanged"].DefaultValue.ExplicitValue, "conflicts should keep their previous base")
+}
+
+func (c *MergeTestSuite) TestWithoutBaseEveryDifferenceConflicts() {
+	local := model.Config{Parameters: map[string]model.Parameter{"same": parameter("1"), "different": parameter("1")}}
+	remote := model.Config{Parameters: map[string]model.Parameter{"same": parameter("1"), "different": parameter("2")}}
+	result := MergeRemote(nil, local, remote)
+	assert.Len(c.T(), result.Conflicts, 1)
+	assert.Equal(c.T(), "different", result.Conflicts[0].Key)
+	assert.Equal(c.T(), "1", result.Parameters["different"].DefaultValue.ExplicitValue)
+}
+
+func (c *MergeTestSuite) TestConditionsKeepLocalOrder() {
+	base := &model.Config{Conditions: []model.Condition{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	local := model.Config{Conditions: []model.Condition{{Name: "c"}, {Name: "a"}, {Name: "b"}}}
+	remote := model.Config{Conditions: []model.Condition{{Name: "a", Expression: "x"}, {Name: "new"}, {Name: "b"}}}
+	result := MergeRemote(base, local, remote)
+	assert.Empty(c.T(), result.Conflicts)
+	assert.Equal(c.T(), []model.Condition{{Name: "a", Expression: "x"}, {Name: "new"}, {Name: "b"}}, result.Conditions)
+}
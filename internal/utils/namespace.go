@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+)
+
+// Ownership decides which parameters of a remote template are managed by a source directory.
+type Ownership struct {
+	namespaces []config.Namespace
+	// remoteGroups maps every key of remote to its parameter group, or to "" for ungrouped parameters.
+	remoteGroups map[string]string
+}
+
+func NewOwnership(namespaces []config.Namespace, remote remoteconfig.RemoteConfig) Ownership {
+	remoteGroups := map[string]string{}
+	for key := range remote.Parameters {
+		remoteGroups[key] = ""
+	}
+	for name, group := range remote.ParameterGroups {
+		for key := range group.Parameters {
+			remoteGroups[key] = name
+		}
+	}
+	return Ownership{namespaces: namespaces, remoteGroups: remoteGroups}
+}
+
+// Owns reports whether key belongs to one of the namespaces.
+// New keys belong to a group namespace only if it is the single group namespace.
+func (o Ownership) Owns(key string) bool {
+	if len(o.namespaces) == 0 || matchesPrefix(o.namespaces, key) {
+		return true
+	}
+	if group, ok := o.remoteGroups[key]; ok {
+		return group != "" && ownsGroup(o.namespaces, group)
+	}
+	return o.GroupForNew(key) != ""
+}
+
+// GroupForNew returns the group a parameter that does not exist on remote is created in,
+// which is the group namespace if it is the only one and key matches no prefix namespace.
+func (o Ownership) GroupForNew(key string) string {
+	if matchesPrefix(o.namespaces, key) {
+		return ""
+	}
+	group := ""
+	for _, namespace := range o.namespaces {
+		if namespace.Group != "" {
+			if group != "" {
+				return ""
+			}
+			group = namespace.Group
+		}
+	}
+	return group
+}
+
+// Validate returns an error for every local parameter outside the owned namespaces.
+func (o Ownership) Validate(parameters map[string]model.Parameter) []error {
+	errs := []error{}
+	for _, key := range sortedParameterKeys(parameters) {
+		if o.Owns(key) {
+			continue
+		}
+		if group, ok := o.remoteGroups[key]; ok && group != "" {
+			msg := fmt.Sprintf("parameter %s belongs to parameter group %s which is outside the owned namespaces", key, group)
+			errs = append(errs, &ValidationError{Parameter: key, Offset: -1, Msg: msg})
+			continue
+		}
+		errs = append(errs, &ValidationError{Parameter: key, Offset: -1, Msg: fmt.Sprintf("parameter %s is outside the owned namespaces", key)})
+	}
+	return errs
+}
+
+// ValidateNamespaces checks without access to remote that every local parameter can belong to a namespace.
+// Membership in a parameter group can only be checked against remote, so with group namespaces the parameters
+// outside every prefix namespace are reported as warnings.
+func ValidateNamespaces(parameters map[string]model.Parameter, namespaces []config.Namespace) []error {
+	errs := []error{}
+	if len(namespaces) == 0 {
+		return errs
+	}
+	groups := []string{}
+	for _, namespace := range namespaces {
+		if namespace.Group != "" {
+			groups = append(groups, namespace.Group)
+		}
+	}
+	for _, key := range sortedParameterKeys(parameters) {
+		if matchesPrefix(namespaces, key) {
+			continue
+		}
+		if len(groups) != 0 {
+			msg := fmt.Sprintf("parameter %s matches no prefix namespace, it must belong to parameter group %s on remote", key, strings.Join(groups, " or "))
+			errs = append(errs, &ValidationError{Parameter: key, Offset: -1, Msg: msg, Warning: true})
+			continue
+		}
+		errs = append(errs, &ValidationError{Parameter: key, Offset: -1, Msg: fmt.Sprintf("parameter %s is outside the owned namespaces", key)})
+	}
+	return errs
+}
+
+// MergeOwnedParameters returns remote with every owned parameter replaced by its version in source.
+func MergeOwnedParameters(source, remote remoteconfig.RemoteConfig, namespaces []config.Namespace) (remoteconfig.RemoteConfig, error) {
+	ownership := NewOwnership(namespaces, remote)
+	return MergeSelectedParameters(source, remote, ownership.Owns, ownership.GroupForNew)
+}
+
+func matchesPrefix(namespaces []config.Namespace, key string) bool {
+	for _, namespace := range namespaces {
+		if namespace.Prefix != "" && strings.HasPrefix(key, namespace.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func ownsGroup(namespaces []config.Namespace, group string) bool {
+	for _, namespace := range namespaces {
+		if namespace.Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedParameterKeys(parameters map[string]model.Parameter) []string {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceTestSuite struct {
+	suite.Suite
+	remote remoteconfig.RemoteConfig
+}
+
+func TestNamespace(t *testing.T) {
+	suite.Run(t, new(NamespaceTestSuite))
+}
+
+func (c *NamespaceTestSuite) SetupTest() {
+	c.remote = remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"payments_limit": {DefaultValue: remoteValue("remote")},
+			"payments_old":   {DefaultValue: remoteValue("remote")},
+			"growth_banner":  {DefaultValue: remoteValue("remote")},
+		},
+		ParameterGroups: map[string]remoteconfig.ParameterGroup{
+			"search": {Parameters: map[string]*remoteconfig.Parameter{"radius": {DefaultValue: remoteValue("remote")}}},
+			"maps":   {Parameters: map[string]*remoteconfig.Parameter{"zoom": {DefaultValue: remoteValue("remote")}}},
+		},
+	}
+}
+
+func (c *NamespaceTestSuite) TestOwnership() {
+	ownership := NewOwnership([]config.Namespace{{Prefix: "payments_"}, {Group: "search"}}, c.remote)
+	assert.True(c.T(), ownership.Owns("payments_limit"))
+	assert.True(c.T(), ownership.Owns("radius"))
+	assert.True(c.T(), ownership.Owns("brand_new"), "new keys go to the only group namespace")
+	assert.False(c.T(), ownership.Owns("growth_banner"))
+	assert.False(c.T(), ownership.Owns("zoom"))
+
+	errs := ownership.Validate(map[string]model.Parameter{"payments_limit": {}, "growth_banner": {}, "zoom": {}})
+	assert.Len(c.T(), errs, 2)
+	assert.Contains(c.T(), errs[0].Error(), "growth_banner is outside the owned namespaces")
+	assert.Contains(c.T(), errs[1].Error(), "zoom belongs to parameter group maps")
+	assert.Equal(c.T(), "zoom", errs[1].(*ValidationError).Parameter)
+}
+
+func (c *NamespaceTestSuite) TestMergeLeavesOtherNamespacesUntouched() {
+	source := remoteconfig.RemoteConfig{Parameters: map[string]remoteconfig.Parameter{
+		"payments_limit": {DefaultValue: remoteValue("local")},
+		"radius":         {DefaultValue: remoteValue("local")},
+		"brand_new":      {DefaultValue: remoteValue("local")},
+	}}
+	merged, err := MergeOwnedParameters(source, c.remote, []config.Namespace{{Prefix: "payments_"}, {Group: "search"}})
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "local", merged.Parameters["payments_limit"].DefaultValue.ExplicitValue)
+	assert.NotContains(c.T(), merged.Parameters, "payments_old")
+	assert.Equal(c.T(), "remote", merged.Parameters["growth_banner"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "local", merged.ParameterGroups["search"].Parameters["radius"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "local", merged.ParameterGroups["search"].Parameters["brand_new"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "remote", merged.ParameterGroups["maps"].Parameters["zoom"].DefaultValue.ExplicitValue)
+}
+
+func (c *NamespaceTestSuite) TestValidateNamespacesOffline() {
+	parameters := map[string]model.Parameter{"payments_limit": {}, "growth_banner": {}}
+	assert.Len(c.T(), ValidateNamespaces(parameters, []config.Namespace{{Prefix: "payments_"}}), 1)
+	assert.Empty(c.T(), ValidateNamespaces(parameters, nil))
+	errs := ValidateNamespaces(parameters, []config.Namespace{{Prefix: "payments_"}, {Group: "growth"}, {Group: "search"}})
+	assert.Len(c.T(), errs, 1)
+	assert.EqualError(c.T(), errs[0], "parameter growth_banner matches no prefix namespace, it must belong to parameter group growth or search on remote")
+	assert.True(c.T(), errs[0].(*ValidationError).Warning)
+}
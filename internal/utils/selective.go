@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+)
+
+// MergeSelectedParameters returns remote with every parameter accepted by selected replaced by its version in
+// source, or removed when source does not define it. The conditions referenced by the selected parameters are
+// added to or updated in remote, keeping the order they have in source. A condition that is also referenced by a
+// parameter that is not selected cannot be changed. Everything else is left as it is on remote.
+// Selected parameters that are new to remote are added to the group returned by groupForNew, if it is not nil,
+// and to the top level otherwise.
+func MergeSelectedParameters(source, remote remoteconfig.RemoteConfig, selected func(key string) bool, groupForNew func(key string) string) (remoteconfig.RemoteConfig, error) {
+	merged := remote
+	merged.Parameters = map[string]remoteconfig.Parameter{}
+	for key, parameter := range remote.Parameters {
+		if !selected(key) {
+			merged.Parameters[key] = parameter
+		}
+	}
+	merged.ParameterGroups = map[string]remoteconfig.ParameterGroup{}
+	grouped := map[string]string{}
+	for name, group := range remote.ParameterGroups {
+		parameters := map[string]*remoteconfig.Parameter{}
+		for key, parameter := range group.Parameters {
+			grouped[key] = name
+			if !selected(key) {
+				parameters[key] = parameter
+			}
+		}
+		merged.ParameterGroups[name] = remoteconfig.ParameterGroup{Description: group.Description, Parameters: parameters}
+	}
+	if remote.ParameterGroups == nil {
+		merged.ParameterGroups = nil
+	}
+
+	neededConditions := map[string]bool{}
+	for key, parameter := range source.Parameters {
+		if !selected(key) {
+			continue
+		}
+		for name := range parameter.ConditionalValues {
+			neededConditions[name] = true
+		}
+		group, ok := grouped[key]
+		if _, inTopLevel := remote.Parameters[key]; !ok && !inTopLevel && groupForNew != nil {
+			group = groupForNew(key)
+		}
+		if group == "" {
+			merged.Parameters[key] = parameter
+			continue
+		}
+		if merged.ParameterGroups == nil {
+			merged.ParameterGroups = map[string]remoteconfig.ParameterGroup{}
+		}
+		if _, ok := merged.ParameterGroups[group]; !ok {
+			merged.ParameterGroups[group] = remoteconfig.ParameterGroup{Parameters: map[string]*remoteconfig.Parameter{}}
+		}
+		p := parameter
+		merged.ParameterGroups[group].Parameters[key] = &p
+	}
+
+	sourceConditions := map[string]remoteconfig.Condition{}
+	for _, condition := range source.Conditions {
+		sourceConditions[condition.Name] = condition
+	}
+	names := make([]string, 0, len(neededConditions))
+	for name := range neededConditions {
+		if _, ok := sourceConditions[name]; !ok {
+			return merged, fmt.Errorf("condition %s is referenced but not defined", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	usedBy := conditionUsers(remote, func(key string) bool { return !selected(key) })
+	for _, name := range names {
+		for _, condition := range remote.Conditions {
+			if condition.Name == name && condition != sourceConditions[name] && usedBy[name] != "" {
+				return merged, fmt.Errorf("condition %s cannot be changed as it is also used by %s, which is not selected", name, usedBy[name])
+			}
+		}
+	}
+	merged.Conditions = append([]remoteconfig.Condition{}, remote.Conditions...)
+	for _, name := range names {
+		merged.Conditions = upsertCondition(merged.Conditions, source.Conditions, sourceConditions[name])
+	}
+	return merged, nil
+}
+
+// conditionUsers returns the first key, in sorted order, of the parameters of rc accepted by include that reference
+// every condition.
+func conditionUsers(rc remoteconfig.RemoteConfig, include func(key string) bool) map[string]string {
+	users := map[string]string{}
+	use := func(key string, parameter remoteconfig.Parameter) {
+		if !include(key) {
+			return
+		}
+		for name := range parameter.ConditionalValues {
+			if user, ok := users[name]; !ok || key < user {
+				users[name] = key
+			}
+		}
+	}
+	for key, parameter := range rc.Parameters {
+		use(key, parameter)
+	}
+	for _, group := range rc.ParameterGroups {
+		for key, parameter := range group.Parameters {
+			if parameter != nil {
+				use(key, *parameter)
+			}
+		}
+	}
+	return users
+}
+
+// upsertCondition replaces the condition with the same name in conditions, or inserts it after the closest
+// condition preceding it in order that is already part of conditions.
+func upsertCondition(conditions, order []remoteconfig.Condition, condition remoteconfig.Condition) []remoteconfig.Condition {
+	for i := range conditions {
+		if conditions[i].Name == condition.Name {
+			conditions[i] = condition
+			return conditions
+		}
+	}
+	position := 0
+	predecessors := []string{}
+	for _, c := range order {
+		if c.Name == condition.Name {
+			break
+		}
+		predecessors = append(predecessors, c.Name)
+	}
+	for j := len(predecessors) - 1; j >= 0 && position == 0; j-- {
+		for k := range conditions {
+			if conditions[k].Name == predecessors[j] {
+				position = k + 1
+				break
+			}
+		}
+	}
+	conditions = append(conditions, remoteconfig.Condition{})
+	copy(conditions[position+1:], conditions[position:])
+	conditions[position] = condition
+	return conditions
+}
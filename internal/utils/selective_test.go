@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SelectiveTestSuite struct {
+	suite.Suite
+}
+
+func TestSelective(t *testing.T) {
+	suite.Run(t, new(SelectiveTestSuite))
+}
+
+func remoteValue(value string) *remoteconfig.ParameterValue {
+	return &remoteconfig.ParameterValue{ExplicitValue: value}
+}
+
+func (c *SelectiveTestSuite) TestOnlySelectedParametersAreReplaced() {
+	source := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "a", Expression: "true"}, {Name: "new", Expression: "false"}, {Name: "b", Expression: "changed"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"selected":   {DefaultValue: remoteValue("local"), ConditionalValues: map[string]*remoteconfig.ParameterValue{"new": remoteValue("x")}},
+			"unselected": {DefaultValue: remoteValue("local")},
+		},
+	}
+	remote := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "a", Expression: "true"}, {Name: "b", Expression: "true"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"selected":   {DefaultValue: remoteValue("remote")},
+			"unselected": {DefaultValue: remoteValue("remote")},
+			"deleted":    {DefaultValue: remoteValue("remote")},
+		},
+	}
+	merged, err := MergeSelectedParameters(source, remote, func(key string) bool {
+		return key == "selected" || key == "deleted"
+	}, nil)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "local", merged.Parameters["selected"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "remote", merged.Parameters["unselected"].DefaultValue.ExplicitValue)
+	assert.NotContains(c.T(), merged.Parameters, "deleted")
+	assert.Equal(c.T(), []remoteconfig.Condition{{Name: "a", Expression: "true"}, {Name: "new", Expression: "false"}, {Name: "b", Expression: "true"}}, merged.Conditions)
+	assert.Len(c.T(), remote.Parameters, 3, "remote should not be modified")
+}
+
+func (c *SelectiveTestSuite) TestMissingConditionErrors() {
+	source := remoteconfig.RemoteConfig{
+		Parameters: map[string]remoteconfig.Parameter{
+			"selected": {DefaultValue: remoteValue("local"), ConditionalValues: map[string]*remoteconfig.ParameterValue{"missing": remoteValue("x")}},
+		},
+	}
+	_, err := MergeSelectedParameters(source, remoteconfig.RemoteConfig{}, func(key string) bool { return true }, nil)
+	assert.Contains(c.T(), err.Error(), "condition missing is referenced but not defined")
+}
+
+func (c *SelectiveTestSuite) TestSharedConditionsCannotChange() {
+	source := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'ios'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"selected": {DefaultValue: remoteValue("local"), ConditionalValues: map[string]*remoteconfig.ParameterValue{"ios": remoteValue("x")}},
+		},
+	}
+	remote := remoteconfig.RemoteConfig{
+		Conditions: []remoteconfig.Condition{{Name: "ios", Expression: "device.os == 'android'"}},
+		Parameters: map[string]remoteconfig.Parameter{
+			"unselected": {DefaultValue: remoteValue("remote"), ConditionalValues: map[string]*remoteconfig.ParameterValue{"ios": remoteValue("y")}},
+		},
+	}
+	selected := func(key string) bool { return key == "selected" }
+	_, err := MergeSelectedParameters(source, remote, selected, nil)
+	assert.EqualError(c.T(), err, "condition ios cannot be changed as it is also used by unselected, which is not selected")
+
+	remote.Conditions[0].Expression = "device.os == 'ios'"
+	merged, err := MergeSelectedParameters(source, remote, selected, nil)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), source.Conditions, merged.Conditions)
+}
+
+func (c *SelectiveTestSuite) TestGroupedParametersStayInTheirGroup() {
+	source := remoteconfig.RemoteConfig{Parameters: map[string]remoteconfig.Parameter{"grouped": {DefaultValue: remoteValue("local")}}}
+	remote := remoteconfig.RemoteConfig{ParameterGroups: map[string]remoteconfig.ParameterGroup{
+		"payments": {Parameters: map[string]*remoteconfig.Parameter{"grouped": {DefaultValue: remoteValue("remote")}}},
+	}}
+	merged, err := MergeSelectedParameters(source, remote, func(key string) bool { return true }, nil)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), merged.Parameters)
+	assert.Equal(c.T(), "local", merged.ParameterGroups["payments"].Parameters["grouped"].DefaultValue.ExplicitValue)
+	assert.Equal(c.T(), "remote", remote.ParameterGroups["payments"].Parameters["grouped"].DefaultValue.ExplicitValue)
+}
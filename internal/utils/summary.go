@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatChangeSummary renders the number of changes of each type, followed by one line per change.
+func FormatChangeSummary(changes ChangeSet) string {
+	sb := strings.Builder{}
+	parameterTypes := []ChangeType{}
+	for _, change := range changes.Parameters {
+		parameterTypes = append(parameterTypes, change.Type)
+	}
+	sb.WriteString("Parameters: " + countByType(parameterTypes) + "\n")
+	for _, change := range changes.Parameters {
+		sb.WriteString(formatChangeLine(change.Type, change.Key))
+	}
+	conditionTypes := []ChangeType{}
+	for _, change := range changes.Conditions {
+		conditionTypes = append(conditionTypes, change.Type)
+	}
+	sb.WriteString("Conditions: " + countByType(conditionTypes) + "\n")
+	for _, change := range changes.Conditions {
+		sb.WriteString(formatChangeLine(change.Type, change.Name))
+	}
+	return sb.String()
+}
+
+func countByType(types []ChangeType) string {
+	counts := map[ChangeType]int{}
+	for _, t := range types {
+		counts[t]++
+	}
+	summary := fmt.Sprintf("%d to add, %d to update, %d to delete", counts[ChangeAdded], counts[ChangeUpdated], counts[ChangeDeleted])
+	if counts[ChangeMoved] != 0 {
+		summary += fmt.Sprintf(", %d to move", counts[ChangeMoved])
+	}
+	return summary
+}
+
+func formatChangeLine(changeType ChangeType, name string) string {
+	switch changeType {
+	case ChangeAdded:
+		return fmt.Sprintf("%s  + %s%s\n", Green, name, Reset)
+	case ChangeDeleted:
+		return fmt.Sprintf("%s  - %s%s\n", Red, name, Reset)
+	case ChangeMoved:
+		return fmt.Sprintf("%s  ↕ %s%s\n", Yellow, name, Reset)
+	default:
+		return fmt.Sprintf("%s  ~ %s%s\n", Yellow, name, Reset)
+	}
+}
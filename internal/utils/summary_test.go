@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SummaryTestSuite struct {
+	suite.Suite
+}
+
+func TestSummary(t *testing.T) {
+	suite.Run(t, new(SummaryTestSuite))
+}
+
+func (c *SummaryTestSuite) TestFormatChangeSummary() {
+	summary := FormatChangeSummary(ChangeSet{
+		Parameters: []ParameterChange{{Key: "a", Type: ChangeAdded}, {Key: "b", Type: ChangeUpdated}, {Key: "c", Type: ChangeDeleted}},
+		Conditions: []ConditionChange{{Name: "d", Type: ChangeMoved}},
+	})
+	assert.Contains(c.T(), summary, "Parameters: 1 to add, 1 to update, 1 to delete\n")
+	assert.Contains(c.T(), summary, "Conditions: 0 to add, 0 to update, 0 to delete, 1 to move\n")
+	assert.Contains(c.T(), summary, "+ a")
+	assert.Contains(c.T(), summary, "~ b")
+	assert.Contains(c.T(), summary, "- c")
+	assert.Contains(c.T(), summary, "↕ d")
+}
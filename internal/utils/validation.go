@@ -3,10 +3,31 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/rapido-labs/firebase-ctl/internal/condition"
 	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"sort"
 	"strings"
 )
 
+// ValidationError is a problem with one parameter or one condition of a config.
+type ValidationError struct {
+	// Parameter is the key of the parameter the problem is about, if any.
+	Parameter string
+	// Condition is the name of the condition the problem is about, if any.
+	Condition string
+	// Path leads from the parameter, or from the condition when there is no parameter, to the value the problem is in.
+	Path []string
+	// Offset is the byte offset of the problem in the decoded string at Path, or -1 for the value as a whole.
+	Offset int
+	Msg    string
+	// Warning marks problems that cannot be confirmed offline, which do not fail the validation.
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
 func ValidateParameters(parameters map[string]model.Parameter) []error {
 	errs := []error{}
 	for k, v := range parameters {
@@ -14,28 +35,88 @@ func ValidateParameters(parameters map[string]model.Parameter) []error {
 		case "string":
 			continue
 		case "json":
-			err := validateJsonParameter(v)
+			path, offset, err := validateJsonParameter(v)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("invalid json for key %s. error:%s", k, err.Error()))
+				errs = append(errs, &ValidationError{Parameter: k, Path: path, Offset: offset, Msg: fmt.Sprintf("invalid json for key %s. error:%s", k, err.Error())})
 			}
 		default:
-			errs = append(errs, fmt.Errorf("invalid value type for key:%s", k))
+			errs = append(errs, &ValidationError{Parameter: k, Path: []string{"valueType"}, Offset: -1, Msg: fmt.Sprintf("invalid value type for key:%s", k)})
 		}
 	}
 	return errs
 }
 
-func validateJsonParameter(parameter model.Parameter) error {
+// validateJsonParameter checks that the values of a json parameter are valid json. On error it returns the path of
+// the invalid value in the parameter and the offset of the problem in it.
+func validateJsonParameter(parameter model.Parameter) ([]string, int, error) {
 	var a json.RawMessage
 	err := json.Unmarshal([]byte(parameter.DefaultValue.ExplicitValue), &a)
 	if err != nil {
-		return fmt.Errorf("invalid json in default value. %s", err.Error())
+		return []string{"defaultValue", "value"}, jsonErrorOffset(err), fmt.Errorf("invalid json in default value. %s", err.Error())
+	}
+	names := make([]string, 0, len(parameter.ConditionalValues))
+	for name := range parameter.ConditionalValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, i := range names {
+		err := json.Unmarshal([]byte(parameter.ConditionalValues[i].ExplicitValue), &a)
+		if err != nil {
+			return []string{"conditionalValues", i, "value"}, jsonErrorOffset(err), fmt.Errorf("invalid json in conditional values. key:%s. error: %s", i, err.Error())
+		}
+	}
+	return nil, -1, nil
+}
+
+// jsonErrorOffset returns the offset of the character a json syntax error is about, or -1 for other errors.
+func jsonErrorOffset(err error) int {
+	syntaxErr, ok := err.(*json.SyntaxError)
+	if !ok {
+		return -1
+	}
+	if syntaxErr.Offset == 0 {
+		return 0
 	}
-	for i, cv := range parameter.ConditionalValues {
-		err := json.Unmarshal([]byte(cv.ExplicitValue), &a)
+	return int(syntaxErr.Offset) - 1
+}
+
+// ValidateConditions checks that condition names are unique, that their expressions parse, and that parameters only
+// have conditional values for defined conditions. Attributes unknown to the condition parser are reported as warnings,
+// since the backend may accept them.
+func ValidateConditions(conditions []model.Condition, parameters map[string]model.Parameter) []error {
+	errs := []error{}
+	defined := map[string]bool{}
+	for _, c := range conditions {
+		if defined[c.Name] {
+			errs = append(errs, &ValidationError{Condition: c.Name, Path: []string{"name"}, Offset: -1, Msg: fmt.Sprintf("duplicate condition %s", c.Name)})
+		}
+		defined[c.Name] = true
+		expr, err := condition.Parse(c.Expression)
 		if err != nil {
-			return fmt.Errorf("invalid json in conditional values. key:%s. error: %s", i, err.Error())
+			errs = append(errs, &ValidationError{Condition: c.Name, Path: []string{"expression"}, Offset: -1, Msg: fmt.Sprintf("invalid expression for condition %s. error: %s", c.Name, err.Error())})
+			continue
+		}
+		for _, name := range condition.UnknownAttributes(expr) {
+			msg := fmt.Sprintf("condition %s uses unknown attribute %s, it cannot be checked or evaluated locally", c.Name, name)
+			errs = append(errs, &ValidationError{Condition: c.Name, Path: []string{"expression"}, Offset: -1, Msg: msg, Warning: true})
 		}
 	}
-	return nil
+	keys := make([]string, 0, len(parameters))
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		names := make([]string, 0, len(parameters[k].ConditionalValues))
+		for name := range parameters[k].ConditionalValues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !defined[name] {
+				errs = append(errs, &ValidationError{Parameter: k, Condition: name, Path: []string{"conditionalValues", name}, Offset: -1, Msg: fmt.Sprintf("undefined condition %s used by key %s", name, k)})
+			}
+		}
+	}
+	return errs
 }
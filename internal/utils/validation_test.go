@@ -74,3 +74,27 @@ func (c *ValidationTestSuite) TestParameters() {
 	assert.Len(c.T(), errs, 3)
 
 }
+
+func (c *ValidationTestSuite) TestConditions() {
+	conditions := []model.Condition{
+		{Name: "ios", Expression: "device.os == 'ios'"},
+		{Name: "ios", Expression: "device.os == 'ios'"},
+		{Name: "broken", Expression: "device.os =="},
+		{Name: "pixel", Expression: "device.model == 'pixel'"},
+	}
+	parameters := map[string]model.Parameter{
+		"key": {ConditionalValues: map[string]model.ParameterValue{"ios": {}, "android": {}}},
+	}
+	errs := ValidateConditions(conditions, parameters)
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(c.T(), []string{
+		"duplicate condition ios",
+		"invalid expression for condition broken. error: expected a value at position 12, found end of expression",
+		"condition pixel uses unknown attribute device.model, it cannot be checked or evaluated locally",
+		"undefined condition android used by key key",
+	}, messages)
+	assert.True(c.T(), errs[2].(*ValidationError).Warning)
+}
@@ -0,0 +1,377 @@
+// Package remoteconfig is the Go API of firebase-ctl. It loads source directories, validates them, and diffs, plans
+// and applies them against a Remote Config backend, returning structured results where the commands print.
+//
+// A typical deployment loads a source, plans the apply, inspects the changes and applies the plan:
+//
+//	client, err := remoteconfig.NewClient(ctx, remoteconfig.Options{})
+//	source, err := client.Load("config/production")
+//	plan, err := client.Plan(source, remoteconfig.PlanOptions{Message: "raise payment limit"})
+//	result, err := client.Apply(source, plan)
+package remoteconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	sdk "github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/audit"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"github.com/rapido-labs/firebase-ctl/internal/lint"
+	"github.com/rapido-labs/firebase-ctl/internal/model"
+	"github.com/rapido-labs/firebase-ctl/internal/notify"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// The types of the sources and results.
+type (
+	Config          = model.Config
+	Parameter       = model.Parameter
+	ParameterValue  = model.ParameterValue
+	ParameterGroup  = model.ParameterGroup
+	Condition       = model.Condition
+	ToolConfig      = config.ToolConfig
+	Target          = config.Target
+	ChangeSet       = utils.ChangeSet
+	ChangeType      = utils.ChangeType
+	ParameterChange = utils.ParameterChange
+	ConditionChange = utils.ConditionChange
+	Finding         = lint.Finding
+	Plan            = firebase.Plan
+	Version         = sdk.Version
+	AuditRecord     = audit.Record
+	AuditQuery      = audit.Query
+)
+
+// The errors of Apply that tell whether the template was published.
+type (
+	// StalePlanError is returned when the live template changed since the plan was computed, nothing was published.
+	StalePlanError = firebase.StalePlanError
+	// UnknownOutcomeError is returned when the publish request got no answer, so the template may or may not have
+	// been published.
+	UnknownOutcomeError = firebase.UnknownOutcomeError
+)
+
+const (
+	ChangeAdded   = utils.ChangeAdded
+	ChangeUpdated = utils.ChangeUpdated
+	ChangeDeleted = utils.ChangeDeleted
+	ChangeMoved   = utils.ChangeMoved
+)
+
+// DefaultProject is the project used with an Endpoint when Options.ProjectID is empty.
+const DefaultProject = firebase.DefaultEmulatorProject
+
+// The defaults of Options.Timeout and Options.MaxRetries.
+var (
+	DefaultTimeout    = firebase.DefaultRetryOptions.Timeout
+	DefaultMaxRetries = firebase.DefaultRetryOptions.MaxRetries
+)
+
+// Options selects the Remote Config backend. The zero value uses the Firebase project of the credentials in
+// GOOGLE_APPLICATION_CREDENTIALS.
+type Options struct {
+	// Endpoint is the base url of a Remote Config REST endpoint to use instead, such as the emulator.
+	Endpoint string
+	// ProjectID is the project used with Endpoint.
+	ProjectID string
+	// CredentialsFile is the service account file used without Endpoint, instead of the one in
+	// GOOGLE_APPLICATION_CREDENTIALS.
+	CredentialsFile string
+	// Timeout bounds every attempt of a remote call. Defaults to 30 seconds.
+	Timeout time.Duration
+	// MaxRetries is the number of times a remote call failing with a transient error is retried, with exponential
+	// backoff. Defaults to 4, negative disables retries. Publishing is only retried on quota errors.
+	MaxRetries int
+}
+
+// Client runs operations on source directories. It is not safe for concurrent use.
+type Client struct {
+	store *firebase.ClientStore
+	ctx   context.Context
+}
+
+// NewClient returns a Client for the backend selected by opts. Its remote calls are cancelled when ctx is done, except
+// for the notifications of an apply, which are sent once the template is published.
+func NewClient(ctx context.Context, opts Options) (*Client, error) {
+	store, err := firebase.GetClientStoreWithOptions(ctx, firebase.ClientOptions{
+		Endpoint:        opts.Endpoint,
+		ProjectID:       opts.ProjectID,
+		CredentialsFile: opts.CredentialsFile,
+		Retry:           firebase.RetryOptions{Timeout: opts.Timeout, MaxRetries: opts.MaxRetries},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{store: store, ctx: ctx}, nil
+}
+
+// ForTarget returns opts with the backend replaced by the one the target of a tool config selects, if any.
+func (opts Options) ForTarget(target Target) Options {
+	if credentials := os.ExpandEnv(target.Credentials); credentials != "" {
+		opts.CredentialsFile = credentials
+		opts.Endpoint, opts.ProjectID = "", ""
+	}
+	if target.Endpoint != "" {
+		opts.Endpoint, opts.ProjectID = target.Endpoint, target.ProjectID
+	}
+	return opts
+}
+
+// Project returns the project the client publishes to, empty when it is not known.
+func (c *Client) Project() string {
+	return c.store.Account().Project
+}
+
+// NewLocalClient returns a Client for the operations that only work on sources, such as Load, Validate, Format and
+// RemoveConditions.
+func NewLocalClient() *Client {
+	return &Client{store: firebase.NewLocalClientStore(), ctx: context.Background()}
+}
+
+// Source is a source directory, loaded along with its tool config.
+type Source struct {
+	Dir    string
+	Config *Config
+	Tool   *ToolConfig
+}
+
+// Load reads the source directory dir.
+func (c *Client) Load(dir string) (*Source, error) {
+	toolConfig, err := config.LoadToolConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.use(toolConfig)
+	localConfig, err := c.store.GetLocalConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Source{Dir: dir, Config: localConfig, Tool: toolConfig}, nil
+}
+
+// use applies the settings of a tool config to the operations that follow.
+func (c *Client) use(toolConfig *ToolConfig) {
+	c.store.SetFormat(toolConfig.Format)
+	c.store.SetNamespaces(toolConfig.Namespaces)
+}
+
+// ValidationResult holds the problems found in a source.
+type ValidationResult struct {
+	// Errors are the problems that the Remote Config API would reject.
+	Errors []error
+	// Warnings are the problems that may be false positives, such as parameters that must be in an owned parameter
+	// group on remote.
+	Warnings []error
+	// Findings are the results of the lint rules enabled in the tool config.
+	Findings []Finding
+}
+
+// OK tells whether the source has no errors and no lint findings of error severity.
+func (r *ValidationResult) OK() bool {
+	return len(r.Errors) == 0 && !lint.HasErrors(r.Findings)
+}
+
+// Validate checks a source offline.
+func (c *Client) Validate(source *Source) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	result.Errors = append(result.Errors, utils.ValidateParameters(source.Config.Parameters)...)
+	errs := utils.ValidateConditions(source.Config.Conditions, source.Config.Parameters)
+	for _, err := range append(errs, utils.ValidateNamespaces(source.Config.Parameters, source.Tool.Namespaces)...) {
+		if v, ok := err.(*utils.ValidationError); ok && v.Warning {
+			result.Warnings = append(result.Warnings, err)
+			continue
+		}
+		result.Errors = append(result.Errors, err)
+	}
+	linter, err := lint.New(source.Tool.Lint)
+	if err != nil {
+		return nil, err
+	}
+	result.Findings = []Finding{}
+	if !linter.Enabled() {
+		return result, nil
+	}
+	// problems reading the sources are reported by Load
+	parameterFiles, conditionFiles, err := c.store.SourceFiles(source.Dir)
+	if _, ok := err.(firebase.SourceErrors); err != nil && !ok {
+		return nil, err
+	}
+	result.Findings = linter.Lint(source.Config, lint.Sources{Parameters: parameterFiles, Conditions: conditionFiles})
+	return result, nil
+}
+
+// ValidateOnRemote has the backend validate the template publishing source results in, without publishing it.
+func (c *Client) ValidateOnRemote(source *Source) error {
+	c.use(source.Tool)
+	return c.store.ValidateOnRemote(*source.Config)
+}
+
+// Watch polls the sources in dir every interval and calls onChange after any of them changes, until ctx is done.
+func (c *Client) Watch(ctx context.Context, dir string, interval time.Duration, onChange func()) {
+	c.store.WatchLocalConfig(ctx, dir, interval, onChange)
+}
+
+// Diff returns the changes applying source would make to the live template.
+func (c *Client) Diff(source *Source) (*ChangeSet, error) {
+	c.use(source.Tool)
+	plan, err := c.store.PlanApply(*source.Config, firebase.Selection{})
+	if err != nil {
+		return nil, err
+	}
+	return &plan.Changes, nil
+}
+
+// PlanOptions controls what an apply changes and the safeguards it must pass.
+type PlanOptions struct {
+	// Only restricts the apply to these parameter keys.
+	Only []string
+	// OnlyFiles restricts the apply to the parameters of these files, relative to the source directory.
+	OnlyFiles []string
+	// AllowDeletes permits deleting parameters and conditions.
+	AllowDeletes bool
+	// MaxChanges overrides apply.maxChanges of the tool config when set.
+	MaxChanges *int
+	// Message starts the description of the published version, which also records the git commit.
+	Message string
+}
+
+// Plan computes the template applying source results in, and checks it against the safeguards.
+func (c *Client) Plan(source *Source, opts PlanOptions) (*Plan, error) {
+	c.use(source.Tool)
+	// broken notifications are reported before anything is published
+	if _, err := notify.New(source.Tool.Notifications); err != nil {
+		return nil, err
+	}
+	keys := append([]string{}, opts.Only...)
+	for _, file := range opts.OnlyFiles {
+		fileKeys, err := c.store.ParameterKeysInFile(source.Dir, file)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if (len(opts.Only) != 0 || len(opts.OnlyFiles) != 0) && len(keys) == 0 {
+		return nil, fmt.Errorf("no parameters selected")
+	}
+	plan, err := c.store.PlanApply(*source.Config, firebase.Selection{Keys: keys, AllowDeletes: opts.AllowDeletes})
+	if err != nil {
+		return nil, err
+	}
+	maxChanges := source.Tool.Apply.MaxChanges
+	if opts.MaxChanges != nil {
+		maxChanges = *opts.MaxChanges
+	}
+	err = plan.Changes.Check(utils.Safeguards{AllowDeletes: opts.AllowDeletes, MaxChanges: maxChanges, Protected: plan.Protected})
+	if err != nil {
+		return nil, &SafeguardError{Problems: err, Changes: plan.Changes}
+	}
+	plan.Description = utils.VersionDescription(opts.Message, utils.GetGitInfo(source.Dir))
+	return plan, nil
+}
+
+// SafeguardError is returned by Plan when the changes violate a safeguard: deleting without AllowDeletes, deleting a
+// protected parameter, or changing more than the maximum number of parameters and conditions.
+type SafeguardError struct {
+	Problems error
+	// Changes are the changes of the refused plan.
+	Changes ChangeSet
+}
+
+func (e *SafeguardError) Error() string {
+	return "refusing to apply: " + e.Problems.Error()
+}
+
+// ApplyResult describes a published template version.
+type ApplyResult struct {
+	Version         Version
+	PreviousVersion int64
+	Changes         ChangeSet
+	// Warnings are problems that did not stop the apply.
+	Warnings []error
+}
+
+// Apply publishes a plan of source, records it in the audit log, then calls the notification webhooks of its tool
+// config. Failing to record the apply or to notify is reported as a warning, as the template is already published.
+// A plan is rejected with a StalePlanError once the live template changed since it was computed. When the publish
+// fails with an UnknownOutcomeError it is recorded in the audit log too, as the template may have been published.
+func (c *Client) Apply(source *Source, plan *Plan) (*ApplyResult, error) {
+	version, err := c.store.PublishPlan(plan)
+	if _, ok := err.(*UnknownOutcomeError); ok {
+		result := &ApplyResult{Version: Version{Description: plan.Description}, PreviousVersion: plan.BaseVersion, Changes: plan.Changes}
+		if auditErr := c.audit(source, plan, result, audit.ActionApplyUnknown); auditErr != nil {
+			return nil, fmt.Errorf("%s, error writing audit log: %s", err.Error(), auditErr.Error())
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &ApplyResult{Version: *version, PreviousVersion: plan.BaseVersion, Changes: plan.Changes}
+	if err := c.audit(source, plan, result, audit.ActionApply); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Errorf("error writing audit log: %s", err.Error()))
+	}
+	if len(source.Tool.Notifications) != 0 {
+		result.Warnings = append(result.Warnings, c.notify(source, result)...)
+	}
+	return result, nil
+}
+
+func (c *Client) notify(source *Source, result *ApplyResult) []error {
+	notifier, err := notify.New(source.Tool.Notifications)
+	if err != nil {
+		return []error{err}
+	}
+	event := notify.NewEvent(source.Dir, result.Version, result.PreviousVersion, result.Changes, utils.GetGitInfo(source.Dir), utils.CurrentUser(source.Dir))
+	return notifier.Notify(event)
+}
+
+func (c *Client) audit(source *Source, plan *Plan, result *ApplyResult, action string) error {
+	changesHash, err := audit.Hash(plan.Changes)
+	if err != nil {
+		return err
+	}
+	planHash, err := audit.Hash(plan.Template)
+	if err != nil {
+		return err
+	}
+	account := c.store.Account()
+	record := audit.Record{
+		Time:            time.Now().UTC(),
+		Action:          action,
+		Identity:        account.Identity,
+		User:            utils.CurrentUser(source.Dir),
+		Project:         account.Project,
+		Directory:       source.Dir,
+		PreviousVersion: result.PreviousVersion,
+		Version:         result.Version.VersionNumber,
+		Summary:         plan.Changes.Summary(),
+		ChangesHash:     changesHash,
+		PlanHash:        planHash,
+		Description:     result.Version.Description,
+	}
+	if git := utils.GetGitInfo(source.Dir); git != nil {
+		record.Commit, record.Branch = git.Commit, git.Branch
+	}
+	return audit.Append(source.Tool.AuditLogPath(source.Dir), record)
+}
+
+// Audit returns the records of the audit log of the source directory dir selected by q, oldest first.
+func (c *Client) Audit(dir string, q AuditQuery) ([]AuditRecord, error) {
+	toolConfig, err := config.LoadToolConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	records, err := audit.Read(toolConfig.AuditLogPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	return audit.Filter(records, q), nil
+}
+
+// History returns up to limit published versions, newest first.
+func (c *Client) History(limit int) ([]Version, error) {
+	return c.store.ListVersions(limit)
+}
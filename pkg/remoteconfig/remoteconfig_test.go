@@ -0,0 +1,295 @@
+package remoteconfig
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rapido-labs/firebase-ctl/internal/emulator"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RemoteConfigTestSuite struct {
+	suite.Suite
+	dir    string
+	server *httptest.Server
+	client *Client
+}
+
+func TestRemoteConfig(t *testing.T) {
+	suite.Run(t, new(RemoteConfigTestSuite))
+}
+
+func (c *RemoteConfigTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "remoteconfig")
+	if err != nil {
+		c.T().Fatal(err)
+	}
+	c.dir = dir
+	c.write("conditions/conditions.json", `[{"name": "ios", "expression": "device.os == 'ios'"}]`)
+	c.write("parameters/parameters.json", `{
+	"banner": {"defaultValue": {"value": "hello"}, "conditionalValues": {"ios": {"value": "hi"}}, "valueType": "string"}
+}`)
+	c.server = httptest.NewServer(emulator.NewServer(afero.NewMemMapFs(), "/data"))
+	c.client, err = NewClient(context.Background(), Options{Endpoint: c.server.URL})
+	assert.NoError(c.T(), err)
+}
+
+func (c *RemoteConfigTestSuite) TearDownTest() {
+	c.server.Close()
+	os.RemoveAll(c.dir)
+}
+
+func (c *RemoteConfigTestSuite) write(relPath, contents string) {
+	path := filepath.Join(c.dir, relPath)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		c.T().Fatal(err)
+	}
+}
+
+func (c *RemoteConfigTestSuite) TestPlanAndApply() {
+	source, err := c.client.Load(c.dir)
+	assert.NoError(c.T(), err)
+
+	validation, err := c.client.Validate(source)
+	assert.NoError(c.T(), err)
+	assert.True(c.T(), validation.OK())
+
+	changes, err := c.client.Diff(source)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), 2, changes.Count())
+
+	plan, err := c.client.Plan(source, PlanOptions{Message: "first"})
+	assert.NoError(c.T(), err)
+	result, err := c.client.Apply(source, plan)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), int64(1), result.Version.VersionNumber)
+	assert.Equal(c.T(), int64(0), result.PreviousVersion)
+	assert.Empty(c.T(), result.Warnings)
+
+	changes, err = c.client.Diff(source)
+	assert.NoError(c.T(), err)
+	assert.True(c.T(), changes.IsEmpty())
+
+	versions, err := c.client.History(10)
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), versions, 1)
+	assert.Equal(c.T(), "first", versions[0].Description)
+}
+
+func (c *RemoteConfigTestSuite) TestGetWritesTheLiveTemplate() {
+	source, _ := c.client.Load(c.dir)
+	plan, _ := c.client.Plan(source, PlanOptions{})
+	c.client.Apply(source, plan)
+
+	outputDir := filepath.Join(c.dir, "copy")
+	assert.NoError(c.T(), c.client.Get(outputDir, WriteOptions{}))
+	copied, err := c.client.Load(outputDir)
+	assert.NoError(c.T(), err)
+	changes, err := c.client.Diff(copied)
+	assert.NoError(c.T(), err)
+	assert.True(c.T(), changes.IsEmpty())
+	unformatted, err := NewLocalClient().Format(outputDir, true)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), unformatted)
+
+	_, err = c.client.Pull(outputDir, WriteOptions{})
+	assert.NoError(c.T(), err)
+	assert.FileExists(c.T(), filepath.Join(outputDir, ".firebase-ctl", "last-pull.json"))
+}
+
+func (c *RemoteConfigTestSuite) TestApplyIsAudited() {
+	c.write("firebase-ctl.json", `{"audit": {"path": "logs/audit.jsonl"}}`)
+	source, err := c.client.Load(c.dir)
+	assert.NoError(c.T(), err)
+	plan, err := c.client.Plan(source, PlanOptions{Message: "first"})
+	assert.NoError(c.T(), err)
+	result, err := c.client.Apply(source, plan)
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), result.Warnings)
+
+	records, err := c.client.Audit(c.dir, AuditQuery{})
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), records, 1)
+	record := records[0]
+	assert.Equal(c.T(), "apply", record.Action)
+	assert.Equal(c.T(), "local", record.Project)
+	assert.Equal(c.T(), c.dir, record.Directory)
+	assert.Equal(c.T(), int64(0), record.PreviousVersion)
+	assert.Equal(c.T(), int64(1), record.Version)
+	assert.Equal(c.T(), "2 added", record.Summary)
+	assert.Equal(c.T(), "first", record.Description)
+	assert.Regexp(c.T(), "^sha256:[0-9a-f]{64}$", record.ChangesHash)
+	assert.Regexp(c.T(), "^sha256:[0-9a-f]{64}$", record.PlanHash)
+	assert.FileExists(c.T(), filepath.Join(c.dir, "logs", "audit.jsonl"))
+
+	records, err = c.client.Audit(c.dir, AuditQuery{Version: 2})
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), records)
+}
+
+func (c *RemoteConfigTestSuite) TestStalePlanIsNotApplied() {
+	source, _ := c.client.Load(c.dir)
+	plan, err := c.client.Plan(source, PlanOptions{})
+	assert.NoError(c.T(), err)
+	concurrent, _ := c.client.Plan(source, PlanOptions{})
+	_, err = c.client.Apply(source, concurrent)
+	assert.NoError(c.T(), err)
+
+	_, err = c.client.Apply(source, plan)
+	_, ok := err.(*StalePlanError)
+	assert.True(c.T(), ok, "a plan computed before the last publish should be rejected")
+}
+
+func (c *RemoteConfigTestSuite) TestUnansweredApplyIsAudited() {
+	c.write("firebase-ctl.json", `{"audit": {"path": "logs/audit.jsonl"}}`)
+	backend := emulator.NewServer(afero.NewMemMapFs(), "/data")
+	// the template is published, but the answer never arrives
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			backend.ServeHTTP(w, r)
+			return
+		}
+		backend.ServeHTTP(httptest.NewRecorder(), r)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client, err := NewClient(context.Background(), Options{Endpoint: server.URL, Timeout: 200 * time.Millisecond})
+	assert.NoError(c.T(), err)
+	source, _ := client.Load(c.dir)
+	plan, err := client.Plan(source, PlanOptions{Message: "first"})
+	assert.NoError(c.T(), err)
+
+	_, err = client.Apply(source, plan)
+	_, ok := err.(*UnknownOutcomeError)
+	assert.True(c.T(), ok, "a publish without an answer should have an unknown outcome")
+	assert.Contains(c.T(), err.Error(), "the template may still have been published")
+	records, err := client.Audit(c.dir, AuditQuery{})
+	assert.NoError(c.T(), err)
+	if assert.Len(c.T(), records, 1) {
+		assert.Equal(c.T(), "apply-unknown", records[0].Action)
+		assert.Equal(c.T(), int64(0), records[0].Version)
+		assert.Contains(c.T(), records[0].Description, "first")
+	}
+}
+
+func (c *RemoteConfigTestSuite) TestOptionsForTarget() {
+	opts := Options{Endpoint: "http://localhost:9010", ProjectID: "local", Timeout: time.Minute}
+	assert.Equal(c.T(), opts, opts.ForTarget(Target{Stage: 1}))
+
+	os.Setenv("TARGET_TEST_CREDENTIALS", "/secrets/eu.json")
+	defer os.Unsetenv("TARGET_TEST_CREDENTIALS")
+	assert.Equal(c.T(), Options{CredentialsFile: "/secrets/eu.json", Timeout: time.Minute},
+		opts.ForTarget(Target{Credentials: "$TARGET_TEST_CREDENTIALS"}))
+	assert.Equal(c.T(), Options{Endpoint: "http://localhost:9020", ProjectID: "eu", Timeout: time.Minute},
+		opts.ForTarget(Target{Endpoint: "http://localhost:9020", ProjectID: "eu"}))
+
+	client, err := NewClient(context.Background(), Options{Endpoint: c.server.URL}.ForTarget(Target{Endpoint: c.server.URL, ProjectID: "eu"}))
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), "eu", client.Project())
+}
+
+func (c *RemoteConfigTestSuite) TestPlanEnforcesSafeguards() {
+	source, _ := c.client.Load(c.dir)
+	plan, _ := c.client.Plan(source, PlanOptions{})
+	c.client.Apply(source, plan)
+
+	c.write("parameters/parameters.json", `{}`)
+	source, err := c.client.Load(c.dir)
+	assert.NoError(c.T(), err)
+	_, err = c.client.Plan(source, PlanOptions{})
+	if refused, ok := err.(*SafeguardError); assert.True(c.T(), ok) {
+		assert.Equal(c.T(), 1, refused.Changes.Count(), "the refused changes should be reported")
+	}
+	_, err = c.client.Plan(source, PlanOptions{AllowDeletes: true})
+	assert.NoError(c.T(), err)
+}
+
+func (c *RemoteConfigTestSuite) TestValidateReportsErrors() {
+	c.write("parameters/parameters.json", `{"banner": {"defaultValue": {"value": "x"}, "conditionalValues": {"web": {"value": "y"}}, "valueType": "string"}}`)
+	source, err := NewLocalClient().Load(c.dir)
+	assert.NoError(c.T(), err)
+	validation, err := NewLocalClient().Validate(source)
+	assert.NoError(c.T(), err)
+	assert.False(c.T(), validation.OK())
+	assert.EqualError(c.T(), validation.Errors[0], "undefined condition web used by key banner")
+}
+
+func (c *RemoteConfigTestSuite) TestExamplesAreValid() {
+	validation, err := NewLocalClient().Report("../../examples")
+	assert.NoError(c.T(), err)
+	assert.Empty(c.T(), validation.Problems)
+}
+
+func (c *RemoteConfigTestSuite) TestReportCollectsEveryLoadError() {
+	c.write("conditions/conditions.json", `[{"name": "ios",`)
+	c.write("parameters/parameters.json", `{"banner": }`)
+	c.write("parameters/more.json", `{"other": {"defaultValue": {"value": "x"}, "valueType": "string"}}`)
+	c.write("parameters/zz.json", `{"other": {"defaultValue": {"value": "y"}, "valueType": "string"}}`)
+
+	validation, err := NewLocalClient().Report(c.dir)
+	assert.NoError(c.T(), err)
+	assert.True(c.T(), validation.HasErrors())
+	files := []string{}
+	for _, problem := range validation.Problems {
+		assert.Equal(c.T(), RuleSource, problem.Rule)
+		files = append(files, problem.File)
+	}
+	assert.Equal(c.T(), []string{
+		filepath.Join(c.dir, "conditions/conditions.json"),
+		filepath.Join(c.dir, "parameters/parameters.json"),
+		filepath.Join(c.dir, "parameters/zz.json"),
+	}, files)
+	assert.Equal(c.T(), "other", validation.Problems[2].Key)
+	assert.Equal(c.T(), []int{1, 17}, []int{validation.Problems[0].Line, validation.Problems[0].Column})
+	assert.Equal(c.T(), []int{1, 12}, []int{validation.Problems[1].Line, validation.Problems[1].Column})
+	assert.Equal(c.T(), []int{1, 2}, []int{validation.Problems[2].Line, validation.Problems[2].Column})
+}
+
+func (c *RemoteConfigTestSuite) TestReportMergesLoadAndValidationErrors() {
+	c.write("parameters/parameters.json", `{"banner": {"defaultValue": {"value": "x"}, "valueType": "number"}}`)
+	c.write("parameters/zz.json", `{"broken": }`)
+	c.write("firebase-ctl.json", `{"namespaces": [{"prefix": "payments_"}], "lint": {"rules": {"description-required": {"severity": "warn"}}}}`)
+
+	validation, err := NewLocalClient().Report(c.dir)
+	assert.NoError(c.T(), err)
+	rules := []string{}
+	for _, problem := range validation.Problems {
+		rules = append(rules, problem.Rule)
+	}
+	assert.ElementsMatch(c.T(), []string{RuleSource, RuleValidation, RuleValidation, "description-required"}, rules)
+}
+
+func (c *RemoteConfigTestSuite) TestReportLocatesProblemsInsideJsonValues() {
+	c.write("parameters/parameters.json", `{
+  "flags": {
+    "defaultValue": {"value": "{\"a\": 1, \"b\": ]}"},
+    "valueType": "json"
+  }
+}`)
+	validation, err := NewLocalClient().Report(c.dir)
+	assert.NoError(c.T(), err)
+	assert.Len(c.T(), validation.Problems, 1)
+	assert.Equal(c.T(), 3, validation.Problems[0].Line)
+	assert.Equal(c.T(), 50, validation.Problems[0].Column)
+}
+
+func (c *RemoteConfigTestSuite) TestReportLocatesValidationErrors() {
+	c.write("parameters/parameters.json", `{"banner": {"defaultValue": {"value": "x"}, "conditionalValues": {"web": {"value": "y"}}, "valueType": "string"}}`)
+	c.write("firebase-ctl.json", `{"lint": {"rules": {"description-required": {"severity": "warn"}}}}`)
+
+	validation, err := NewLocalClient().Report(c.dir)
+	assert.NoError(c.T(), err)
+	assert.Equal(c.T(), []Problem{
+		{Severity: "warn", File: filepath.Join(c.dir, "parameters/parameters.json"), Line: 1, Column: 2, Key: "banner", Rule: "description-required", Message: validation.Problems[0].Message},
+		{Severity: "error", File: filepath.Join(c.dir, "parameters/parameters.json"), Line: 1, Column: 67, Key: "banner", Rule: RuleValidation, Message: "undefined condition web used by key banner"},
+	}, validation.Problems)
+}
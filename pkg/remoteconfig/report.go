@@ -0,0 +1,93 @@
+package remoteconfig
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"github.com/rapido-labs/firebase-ctl/internal/report"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// The types of validation reports.
+type (
+	Report  = report.Report
+	Problem = report.Problem
+)
+
+// Rules of the problems that are not found by lint rules.
+const (
+	RuleSource           = "source"
+	RuleValidation       = "validation"
+	RuleRemoteValidation = "remote-validation"
+)
+
+// Report loads and validates the source directory dir offline, collecting every problem instead of stopping at the
+// first one. When some sources cannot be read, the problems found while reading them are reported along with the
+// ones of the definitions that could be read.
+func (c *Client) Report(dir string) (*Report, error) {
+	r := &Report{}
+	toolConfig, err := config.LoadToolConfig(dir)
+	if err != nil {
+		r.Add(Problem{Severity: config.SeverityError, File: filepath.Join(dir, config.ToolConfigFile), Rule: RuleSource, Message: err.Error()})
+		return r, nil
+	}
+	c.use(toolConfig)
+	localConfig, err := c.store.GetLocalConfig(dir)
+	errs, ok := err.(firebase.SourceErrors)
+	if err != nil && !ok {
+		return nil, err
+	}
+	conditionsUnread := false
+	for _, e := range errs {
+		problem := Problem{Severity: config.SeverityError, File: e.File, Line: e.Line, Column: e.Column, Key: e.Key, Rule: RuleSource, Message: e.Err.Error()}
+		if problem.Line == 0 && problem.Key != "" {
+			problem.Line, problem.Column, _ = c.store.Locate(problem.File, problem.Key, nil, -1)
+		}
+		if strings.HasPrefix(e.File, filepath.Join(dir, config.ConditionsDir)) {
+			conditionsUnread = true
+		}
+		r.Add(problem)
+	}
+	source := &Source{Dir: dir, Config: localConfig, Tool: toolConfig}
+	result, err := c.Validate(source)
+	if err != nil {
+		return nil, err
+	}
+	parameterFiles, conditionFiles, err := c.store.SourceFiles(dir)
+	if _, ok := err.(firebase.SourceErrors); err != nil && !ok {
+		return nil, err
+	}
+	for _, e := range append(result.Errors, result.Warnings...) {
+		problem := Problem{Severity: config.SeverityError, Rule: RuleValidation, Message: e.Error()}
+		if v, ok := e.(*utils.ValidationError); ok {
+			if conditionsUnread && v.Parameter != "" && v.Condition != "" {
+				// the condition may be defined in the sources that could not be read
+				continue
+			}
+			if v.Warning {
+				problem.Severity = config.SeverityWarn
+			}
+			problem.Key, problem.File = v.Parameter, parameterFiles[v.Parameter]
+			if v.Parameter == "" {
+				problem.Key, problem.File = v.Condition, conditionFiles[v.Condition]
+			}
+			if problem.File != "" {
+				problem.File = filepath.Join(dir, problem.File)
+				problem.Line, problem.Column, _ = c.store.Locate(problem.File, problem.Key, v.Path, v.Offset)
+			}
+		}
+		r.Add(problem)
+	}
+	for _, f := range result.Findings {
+		problem := Problem{Severity: f.Severity, Key: f.Key, Rule: f.Rule, Message: f.Message}
+		if f.File != "" {
+			problem.File = filepath.Join(dir, f.File)
+			problem.Line, problem.Column, _ = c.store.Locate(problem.File, problem.Key, nil, -1)
+		}
+		r.Add(problem)
+	}
+	r.Sort()
+	return r, nil
+}
@@ -0,0 +1,93 @@
+package remoteconfig
+
+import (
+	"time"
+
+	sdk "github.com/rapido-labs/firebase-admin-go/v4/remoteconfig"
+	"github.com/rapido-labs/firebase-ctl/internal/config"
+	"github.com/rapido-labs/firebase-ctl/internal/firebase"
+	"github.com/rapido-labs/firebase-ctl/internal/utils"
+)
+
+// The types of the operations that write source directories.
+type (
+	Template         = sdk.RemoteConfig
+	WriteOptions     = firebase.BackupOptions
+	PullResult       = utils.MergeResult
+	PullConflict     = utils.Conflict
+	ExpiredCondition = firebase.ExpiredCondition
+)
+
+// The layouts of the files written to source directories, see WriteOptions.
+const (
+	SplitByNone   = firebase.SplitByNone
+	SplitByPrefix = firebase.SplitByPrefix
+	LayoutDefault = firebase.LayoutDefault
+	LayoutPrefix  = firebase.LayoutPrefix
+	LayoutGroup   = firebase.LayoutGroup
+)
+
+// useDir applies the tool config of the source directory dir to the operations that follow.
+func (c *Client) useDir(dir string) error {
+	toolConfig, err := config.LoadToolConfig(dir)
+	if err != nil {
+		return err
+	}
+	c.use(toolConfig)
+	return nil
+}
+
+// Remote returns the live template.
+func (c *Client) Remote() (*Template, error) {
+	return c.store.GetLatestRemoteConfig()
+}
+
+// Get writes the live template to the sources in dir, replacing their parameters and conditions.
+func (c *Client) Get(dir string, opts WriteOptions) error {
+	if err := c.useDir(dir); err != nil {
+		return err
+	}
+	remote, err := c.store.GetLatestRemoteConfig()
+	if err != nil {
+		return err
+	}
+	return c.store.BackupRemoteConfig(remote, dir, opts)
+}
+
+// Pull merges the changes made to the live template since the last pull into the sources in dir. Entries changed
+// both locally and on remote are left alone and reported as conflicts.
+func (c *Client) Pull(dir string, opts WriteOptions) (*PullResult, error) {
+	if err := c.useDir(dir); err != nil {
+		return nil, err
+	}
+	remote, err := c.store.GetLatestRemoteConfig()
+	if err != nil {
+		return nil, err
+	}
+	return c.store.PullRemoteConfig(remote, dir, opts)
+}
+
+// Format rewrites the files of the source directory dir in the canonical format and returns the ones that were not
+// formatted. With check set nothing is written.
+func (c *Client) Format(dir string, check bool) ([]string, error) {
+	if err := c.useDir(dir); err != nil {
+		return nil, err
+	}
+	return c.store.FormatLocalConfig(dir, check)
+}
+
+// ExpiredConditions returns the conditions of the source directory dir that can never match after now.
+func (c *Client) ExpiredConditions(dir string, now time.Time) ([]ExpiredCondition, error) {
+	if err := c.useDir(dir); err != nil {
+		return nil, err
+	}
+	return c.store.FindExpiredConditions(dir, now)
+}
+
+// RemoveConditions deletes the named conditions and their conditional values from the source directory dir.
+func (c *Client) RemoveConditions(dir string, names []string) error {
+	if err := c.useDir(dir); err != nil {
+		return err
+	}
+	return c.store.RemoveConditions(dir, names)
+}